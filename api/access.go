@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type privilegedKey struct{}
+
+const bearerPrefix = "Bearer "
+
+// WithPrivilege returns a copy of ctx recording whether the caller may use
+// privileged operations, such as looking at cards that have not been dealt.
+func WithPrivilege(ctx context.Context, privileged bool) context.Context {
+	return context.WithValue(ctx, privilegedKey{}, privileged)
+}
+
+func isPrivileged(ctx context.Context) bool {
+	privileged, _ := ctx.Value(privilegedKey{}).(bool)
+	return privileged
+}
+
+// HasAdminToken reports whether r is authorized with the bearer token
+// adminToken, in an "Authorization: Bearer <token>" header. If adminToken is
+// empty no request is, so privileged operations stay closed until a token is
+// configured.
+func HasAdminToken(r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		return false
+	}
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return false
+	}
+	token := header[len(bearerPrefix):]
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// revealRequested reports whether r asks for the cards of a deck or pile
+// with ?reveal=true. Only privileged callers may see them.
+func revealRequested(r *http.Request, ctx context.Context) (bool, int, error) {
+	param := r.URL.Query().Get("reveal")
+	if param == "" {
+		return false, 0, nil
+	}
+	reveal, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, http.StatusBadRequest, ApiError{Message: "reveal must be true or false"}
+	}
+	if reveal && !isPrivileged(ctx) {
+		return false, http.StatusForbidden, ApiError{Message: "Revealing the cards requires the admin token"}
+	}
+	return reveal, 0, nil
+}
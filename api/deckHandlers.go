@@ -3,42 +3,192 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	db "github.com/AbhilashJN/cards/database"
 	"github.com/AbhilashJN/cards/deck"
 	"github.com/google/uuid"
 	"github.com/julienschmidt/httprouter"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// defaultListLimit and maxListLimit bound how many decks a listing returns
+// per page.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
 type CreateDeckRequestBody struct {
-	Shuffle     bool     `json:"shuffle"`
-	CustomDeck  bool     `json:"customDeck"`
-	WantedCards []string `json:"wantedCards"`
+	Shuffle       bool     `json:"shuffle"`
+	CustomDeck    bool     `json:"customDeck"`
+	WantedCards   []string `json:"wantedCards"`
+	Decks         int      `json:"decks"`
+	IncludeJokers bool     `json:"includeJokers"`
+	Seed          *int64   `json:"seed"`
+	RNG           string   `json:"rng"`
+	ClientSeed    string   `json:"clientSeed"`
+	ShuffleMethod string   `json:"shuffleMethod"`
+	Passes        int      `json:"passes"`
+	Owner         string   `json:"owner"`
+	Tags          []string `json:"tags"`
+	Family        string   `json:"family"`
+	Preset        string   `json:"preset"`
+	ExcludeRanks  []string `json:"excludeRanks"`
+	ExcludeSuits  []string `json:"excludeSuits"`
+}
+
+// exclusions decodes the rank and suit codes to leave out of the deck.
+func (b CreateDeckRequestBody) exclusions() ([]deck.CardValue, []deck.CardSuit, error) {
+	var (
+		values []deck.CardValue
+		suits  []deck.CardSuit
+	)
+	for _, code := range b.ExcludeRanks {
+		value, err := deck.DecodeValue(code)
+		if err != nil {
+			return nil, nil, ApiError{Message: err.Error()}
+		}
+		values = append(values, value)
+	}
+	for _, code := range b.ExcludeSuits {
+		suit, err := deck.DecodeSuit(code)
+		if err != nil {
+			return nil, nil, ApiError{Message: err.Error()}
+		}
+		suits = append(suits, suit)
+	}
+	return values, suits, nil
 }
 
 type CreateDeckResponseBody struct {
 	DeckId    string `json:"deck_id"`
 	Shuffled  bool   `json:"shuffled"`
 	Remaining int    `json:"remaining"`
+	Seed      *int64 `json:"seed,omitempty"`
+	RNG       string `json:"rng"`
+	// ServerSeedHash and ClientSeed are only set for fair decks.
+	ServerSeedHash string `json:"server_seed_hash,omitempty"`
+	ClientSeed     string `json:"client_seed,omitempty"`
 }
 
 type GetDeckResponseBody struct {
+	DeckId        string    `json:"deck_id"`
+	Shuffled      bool      `json:"shuffled"`
+	Remaining     int       `json:"remaining"`
+	ShuffleMethod string    `json:"shuffle_method,omitempty"`
+	Passes        int       `json:"passes,omitempty"`
+	Seed          *int64    `json:"seed,omitempty"`
+	RNG           string    `json:"rng,omitempty"`
+	CustomDeck    bool      `json:"custom_deck"`
+	WantedCards   []string  `json:"wanted_cards,omitempty"`
+	Family        string    `json:"family,omitempty"`
+	Preset        string    `json:"preset,omitempty"`
+	ExcludeRanks  []string  `json:"exclude_ranks,omitempty"`
+	ExcludeSuits  []string  `json:"exclude_suits,omitempty"`
+	Decks         int       `json:"decks,omitempty"`
+	IncludeJokers bool      `json:"include_jokers"`
+	OriginalSize  int       `json:"original_size"`
+	CreatedAt     time.Time `json:"created_at"`
+	Closed        bool      `json:"closed"`
+	LastActivity  time.Time `json:"last_activity"`
+	Owner         string    `json:"owner,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	// ServerSeedHash and ClientSeed are only set for fair decks.
+	ServerSeedHash string `json:"server_seed_hash,omitempty"`
+	ClientSeed     string `json:"client_seed,omitempty"`
+	// Cards is only set when the caller asked for the cards to be revealed.
+	Cards   deck.DeckJSON `json:"cards,omitempty"`
+	Version int64         `json:"-"`
+}
+
+type ListDecksResponseBody struct {
+	Decks []GetDeckResponseBody `json:"decks"`
+	// NextCursor is only set if there are more decks to list.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+type DrawCardsRequestBody struct {
+	NumberOfCards int      `json:"numberOfCards"`
+	From          string   `json:"from"`
+	Cards         []string `json:"cards"`
+}
+
+type DrawCardsResponseBody struct {
+	Cards   deck.DeckJSON `json:"cards"`
+	Version int64         `json:"-"`
+}
+
+type ReturnCardsRequestBody struct {
+	Cards []string `json:"cards"`
+}
+
+type ReturnCardsResponseBody struct {
 	DeckId    string        `json:"deck_id"`
-	Shuffled  bool          `json:"shuffled"`
 	Remaining int           `json:"remaining"`
 	Cards     deck.DeckJSON `json:"cards"`
+	Version   int64         `json:"-"`
 }
 
-type DrawCardsRequestBody struct {
-	NumberOfCards int `json:"numberOfCards"`
+type ShuffleDeckRequestBody struct {
+	ShuffleMethod string `json:"shuffleMethod"`
+	Passes        int    `json:"passes"`
+	Position      *int   `json:"position"`
 }
 
-type DrawCardsResponseBody struct {
-	Cards deck.DeckJSON `json:"cards"`
+type ShuffleDeckResponseBody struct {
+	DeckId    string `json:"deck_id"`
+	Shuffled  bool   `json:"shuffled"`
+	Remaining int    `json:"remaining"`
+	Version   int64  `json:"-"`
+}
+
+type CloseDeckResponseBody struct {
+	DeckId    string `json:"deck_id"`
+	Closed    bool   `json:"closed"`
+	Remaining int    `json:"remaining"`
+	Version   int64  `json:"-"`
+}
+
+type DeleteDeckResponseBody struct {
+	DeckId  string `json:"deck_id"`
+	Deleted bool   `json:"deleted"`
+}
+
+type PeekCardsResponseBody struct {
+	DeckId    string        `json:"deck_id"`
+	Remaining int           `json:"remaining"`
+	Cards     deck.DeckJSON `json:"cards"`
+	Version   int64         `json:"-"`
+}
+
+type RevealDeckResponseBody struct {
+	DeckId         string        `json:"deck_id"`
+	ServerSeed     string        `json:"server_seed"`
+	ServerSeedHash string        `json:"server_seed_hash"`
+	ClientSeed     string        `json:"client_seed"`
+	Unshuffled     deck.DeckJSON `json:"unshuffled"`
+	Shuffled       deck.DeckJSON `json:"shuffled"`
+	Version        int64         `json:"-"`
+}
+
+type SeedDeckRequestBody struct {
+	ClientSeed string `json:"clientSeed"`
+}
+
+type SeedDeckResponseBody struct {
+	DeckId         string `json:"deck_id"`
+	Shuffled       bool   `json:"shuffled"`
+	Remaining      int    `json:"remaining"`
+	ServerSeedHash string `json:"server_seed_hash"`
+	ClientSeed     string `json:"client_seed"`
+	Version        int64  `json:"-"`
 }
 
 type ApiError struct {
@@ -49,6 +199,31 @@ func (e ApiError) Error() string {
 	return e.Message
 }
 
+// ETag formats a deck version as a strong entity tag.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the deck version a request is conditional on, or
+// db.AnyVersion if the request is unconditional. A tag that is not a deck
+// version can never match, so it is reported as an error. Decks stored before
+// versions were introduced have the ETag "0", which matches them like any
+// other version.
+func parseIfMatch(r *http.Request) (int64, error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return db.AnyVersion, nil
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, db.ErrVersionMismatch
+	}
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 0 {
+		return 0, db.ErrVersionMismatch
+	}
+	return version, nil
+}
+
 func HandleCreateDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (CreateDeckResponseBody, int, error) {
 	var (
 		reqBody      CreateDeckRequestBody
@@ -63,16 +238,97 @@ func HandleCreateDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, c
 		return responseBody, http.StatusBadRequest, ApiError{Message: "List of wanted cards must be provided for custom deck"}
 	}
 
+	excludeValues, excludeSuits, err := reqBody.exclusions()
+	if err != nil {
+		return responseBody, http.StatusBadRequest, err
+	}
+
+	rng := deck.RNG(reqBody.RNG)
+	if rng == "" {
+		rng = deck.RNGMath
+	}
 	deckId := uuid.NewString()
-	cards, err := deck.New(&deck.NewDeckOpts{
+	opts := deck.NewDeckOpts{
 		Shuffle:         reqBody.Shuffle,
 		CustomDeck:      reqBody.CustomDeck,
 		CustomDeckCards: reqBody.WantedCards,
-	})
-	deckItem := db.DeckModel{UUID: deckId, Cards: cards}
+		Family:          deck.Family(reqBody.Family),
+		Preset:          deck.Preset(reqBody.Preset),
+		ExcludeValues:   excludeValues,
+		ExcludeSuits:    excludeSuits,
+		Decks:           reqBody.Decks,
+		IncludeJokers:   reqBody.IncludeJokers,
+		Seed:            reqBody.Seed,
+		RNG:             rng,
+		ClientSeed:      reqBody.ClientSeed,
+		ShuffleMethod:   deck.ShuffleMethod(reqBody.ShuffleMethod),
+		Passes:          reqBody.Passes,
+	}
+	if rng == deck.RNGFair {
+		// The server seed is fixed and its commitment published before the
+		// players choose their seed, otherwise the server could try seeds
+		// until it likes the order. The deck is shuffled once the client
+		// seed arrives, see HandleSeedDeck.
+		if reqBody.ClientSeed != "" {
+			return responseBody, http.StatusBadRequest, ApiError{Message: "The client seed of a fair deck must be sent to /deck/{deck_id}/seed once the deck is created"}
+		}
+		opts.Shuffle = false
+		opts.ServerSeed = deck.NewServerSeed()
+	}
+	cards, err := deck.New(&opts)
 	if err != nil {
 		return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
 	}
+	deckItem := db.DeckModel{
+		UUID:          deckId,
+		Cards:         cards,
+		Shuffled:      opts.Shuffle,
+		Original:      cards,
+		Seed:          reqBody.Seed,
+		RNG:           rng,
+		Decks:         reqBody.Decks,
+		IncludeJokers: reqBody.IncludeJokers,
+		CreatedAt:     time.Now().UTC(),
+		OriginalSize:  len(cards),
+		Owner:         reqBody.Owner,
+		Tags:          reqBody.Tags,
+		Family:        opts.Family,
+		Preset:        opts.Preset,
+		Version:       1,
+	}
+	for _, value := range excludeValues {
+		deckItem.ExcludeRanks = append(deckItem.ExcludeRanks, value.Code())
+	}
+	for _, suit := range excludeSuits {
+		deckItem.ExcludeSuits = append(deckItem.ExcludeSuits, suit.Code())
+	}
+	deckItem.LastActivity = deckItem.CreatedAt
+	if deckItem.Decks == 0 {
+		deckItem.Decks = 1
+	}
+	if opts.Shuffle {
+		deckItem.ShuffleMethod, deckItem.Passes = opts.ShuffleMethod, opts.Passes
+		if deckItem.ShuffleMethod == "" {
+			deckItem.ShuffleMethod = deck.ShuffleRandom
+		}
+		if deckItem.Passes == 0 {
+			deckItem.Passes = 1
+		}
+	}
+	if opts.CustomDeck {
+		deckItem.WantedCards = make([]string, len(reqBody.WantedCards))
+		for i, cardCode := range reqBody.WantedCards {
+			value, suit, _ := deck.DecodeValueAndSuit(cardCode)
+			deckItem.WantedCards[i] = deck.Card{Value: value, Suit: suit}.Code()
+		}
+	}
+	if rng == deck.RNGFair {
+		deckItem.Fair = &db.FairShuffleModel{
+			ServerSeed: opts.ServerSeed,
+			Commitment: deck.Commitment(opts.ServerSeed),
+			Unshuffled: append(deck.Deck{}, cards...),
+		}
+	}
 
 	err = dc.InsertDeck(ctx, deckItem)
 	if err != nil {
@@ -81,8 +337,14 @@ func HandleCreateDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, c
 	}
 
 	responseBody.DeckId = deckId
-	responseBody.Shuffled = reqBody.Shuffle
+	responseBody.Shuffled = opts.Shuffle
 	responseBody.Remaining = len(cards)
+	responseBody.Seed = reqBody.Seed
+	responseBody.RNG = string(rng)
+	if deckItem.Fair != nil {
+		responseBody.ServerSeedHash = deckItem.Fair.Commitment
+		responseBody.ClientSeed = deckItem.Fair.ClientSeed
+	}
 	return responseBody, http.StatusCreated, nil
 }
 
@@ -92,26 +354,119 @@ func HandleGetDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx
 		resultDeck   db.DeckModel
 	)
 	reqUUID := ps.ByName("uuid")
-	resultDeck, err := dc.FindDeckByUUID(ctx, reqUUID)
-	if err == mongo.ErrNoDocuments {
-		return responseBody, http.StatusNotFound, ApiError{Message: "Deck with this id does not exist"}
-	} else if err != nil {
-		log.Println("Error occurred while searching for document in db.", err)
-		return responseBody, http.StatusInternalServerError, ApiError{Message: "Internal Server Error"}
+	reveal, code, err := revealRequested(r, ctx)
+	if err != nil {
+		return responseBody, code, err
+	}
+	resultDeck, err = dc.FindDeckByUUID(ctx, reqUUID)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
 	}
 
-	responseBody.DeckId = resultDeck.UUID
-	responseBody.Shuffled = resultDeck.Shuffled
-	responseBody.Remaining = len(resultDeck.Cards)
-	responseBody.Cards = resultDeck.Cards.ToDeckJSON()
+	responseBody = deckDetails(resultDeck)
+	if reveal {
+		responseBody.Cards = resultDeck.Cards.ToDeckJSON()
+	}
 	return responseBody, http.StatusOK, nil
 }
 
+// deckDetails describes d without its cards.
+func deckDetails(d db.DeckModel) GetDeckResponseBody {
+	details := GetDeckResponseBody{
+		DeckId:        d.UUID,
+		Shuffled:      d.Shuffled,
+		Remaining:     len(d.Cards),
+		ShuffleMethod: string(d.ShuffleMethod),
+		Passes:        d.Passes,
+		Seed:          d.Seed,
+		RNG:           string(d.RNG),
+		CustomDeck:    len(d.WantedCards) > 0,
+		WantedCards:   d.WantedCards,
+		Family:        string(d.Family),
+		Preset:        string(d.Preset),
+		ExcludeRanks:  d.ExcludeRanks,
+		ExcludeSuits:  d.ExcludeSuits,
+		Decks:         d.Decks,
+		IncludeJokers: d.IncludeJokers,
+		OriginalSize:  d.OriginalSize,
+		CreatedAt:     d.CreatedAt,
+		Closed:        d.Closed,
+		LastActivity:  d.LastActivity,
+		Owner:         d.Owner,
+		Tags:          d.Tags,
+		Version:       d.Version,
+	}
+	if d.Fair != nil {
+		details.ServerSeedHash = d.Fair.Commitment
+		details.ClientSeed = d.Fair.ClientSeed
+	}
+	return details
+}
+
+// HandleListDecks lists the decks matching the query parameters, oldest
+// first, a page at a time. It is meant for operators looking for stuck or
+// abandoned decks, so it is a privileged operation.
+func HandleListDecks(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (ListDecksResponseBody, int, error) {
+	var responseBody ListDecksResponseBody
+	if !isPrivileged(ctx) {
+		return responseBody, http.StatusForbidden, ApiError{Message: "Listing decks requires the admin token"}
+	}
+	opts, err := listDecksOpts(r.URL.Query())
+	if err != nil {
+		return responseBody, http.StatusBadRequest, err
+	}
+
+	page, err := dc.ListDecks(ctx, opts)
+	if err == db.ErrInvalidCursor {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "cursor must be a next_cursor returned by a previous request"}
+	}
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.Decks = make([]GetDeckResponseBody, len(page.Decks))
+	for i, d := range page.Decks {
+		responseBody.Decks[i] = deckDetails(d)
+	}
+	responseBody.NextCursor = page.NextCursor
+	return responseBody, http.StatusOK, nil
+}
+
+// listDecksOpts validates the query parameters of a deck listing.
+func listDecksOpts(query url.Values) (db.ListDecksOpts, error) {
+	opts := db.ListDecksOpts{
+		Filter: db.DeckFilter{Owner: query.Get("owner"), Tag: query.Get("tag")},
+		Limit:  defaultListLimit,
+		After:  query.Get("cursor"),
+	}
+	if param := query.Get("created_after"); param != "" {
+		createdAfter, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return opts, ApiError{Message: "created_after must be a time in RFC 3339 format"}
+		}
+		opts.Filter.CreatedAfter = createdAfter
+	}
+	if param := query.Get("remaining_below"); param != "" {
+		remainingBelow, err := strconv.Atoi(param)
+		if err != nil || remainingBelow <= 0 {
+			return opts, ApiError{Message: "remaining_below must be greater than 0"}
+		}
+		opts.Filter.RemainingBelow = &remainingBelow
+	}
+	if param := query.Get("limit"); param != "" {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit <= 0 || limit > maxListLimit {
+			return opts, ApiError{Message: "limit must be between 1 and " + strconv.Itoa(maxListLimit)}
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
 func HandleDrawCards(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (DrawCardsResponseBody, int, error) {
 	var (
 		reqBody      DrawCardsRequestBody
 		responseBody DrawCardsResponseBody
-		resultDeck   db.DeckModel
 	)
 	reqUUID := ps.ByName("uuid")
 	err := json.NewDecoder(r.Body).Decode(&reqBody)
@@ -119,30 +474,284 @@ func HandleDrawCards(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ct
 		log.Println("Error parsing request body", err)
 		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
 	}
-	if reqBody.NumberOfCards <= 0 {
-		return responseBody, http.StatusBadRequest, ApiError{Message: "Number of cards must be specified and be greater than 0"}
+	drawOpts, err := reqBody.drawOpts()
+	if err != nil {
+		return responseBody, http.StatusBadRequest, err
+	}
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
 
+	drawnCards, updatedDeck, err := db.DrawCardsByUUID(ctx, dc, reqUUID, drawOpts, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
 	}
 
-	resultDeck, err = dc.FindDeckByUUID(ctx, reqUUID)
-	if err == mongo.ErrNoDocuments {
-		return responseBody, http.StatusNotFound, ApiError{Message: "Deck with this id does not exist"}
-	} else if err != nil {
-		log.Println("Error occurred while searching for document in db.", err)
-		return responseBody, http.StatusInternalServerError, ApiError{Message: "Internal Server Error"}
+	responseBody.Cards = drawnCards.ToDeckJSON()
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// drawOpts validates the request and returns the draw it describes.
+func (b DrawCardsRequestBody) drawOpts() (*deck.DrawOpts, error) {
+	if len(b.Cards) > 0 {
+		if b.NumberOfCards != 0 || b.From != "" {
+			return nil, ApiError{Message: "A list of cards cannot be combined with numberOfCards or from"}
+		}
+		return &deck.DrawOpts{Cards: b.Cards}, nil
+	}
+	if b.NumberOfCards <= 0 {
+		return nil, ApiError{Message: "Number of cards must be specified and be greater than 0"}
 	}
+	return &deck.DrawOpts{Mode: deck.DrawMode(b.From), NumberOfCards: b.NumberOfCards}, nil
+}
 
-	drawnCards, remainingCards, err := deck.DrawCards(resultDeck.Cards, reqBody.NumberOfCards)
+func HandleReturnCards(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (ReturnCardsResponseBody, int, error) {
+	var (
+		reqBody      ReturnCardsRequestBody
+		responseBody ReturnCardsResponseBody
+	)
+	reqUUID := ps.ByName("uuid")
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil && err != io.EOF {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	ifVersion, err := parseIfMatch(r)
 	if err != nil {
-		return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
+
+	returnedCards, updatedDeck, err := db.ReturnCardsByUUID(ctx, dc, reqUUID, reqBody.Cards, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = updatedDeck.UUID
+	responseBody.Remaining = len(updatedDeck.Cards)
+	responseBody.Cards = returnedCards.ToDeckJSON()
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+func HandleShuffleDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (ShuffleDeckResponseBody, int, error) {
+	var (
+		reqBody      ShuffleDeckRequestBody
+		responseBody ShuffleDeckResponseBody
+		updatedDeck  db.DeckModel
+	)
+	reqUUID := ps.ByName("uuid")
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil && err != io.EOF {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	method := deck.ShuffleMethod(reqBody.ShuffleMethod)
+	if reqBody.Position != nil && method != deck.ShuffleCut {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "A position can only be given with the cut shuffle method"}
 	}
-	updateQuery := bson.D{{Key: "$set", Value: bson.D{{Key: "cards", Value: remainingCards}}}}
-	err = dc.UpdateDeckByUUID(ctx, reqUUID, updateQuery)
+	ifVersion, err := parseIfMatch(r)
 	if err != nil {
-		log.Println("Error occurred while updating document in db.", err)
-		return responseBody, http.StatusInternalServerError, ApiError{Message: "Internal Server Error"}
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
 	}
 
-	responseBody.Cards = drawnCards.ToDeckJSON()
+	if reqBody.Position != nil {
+		updatedDeck, err = db.CutByUUID(ctx, dc, reqUUID, *reqBody.Position, ifVersion)
+	} else {
+		updatedDeck, err = db.ShuffleByUUID(ctx, dc, reqUUID, method, reqBody.Passes, ifVersion)
+	}
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = updatedDeck.UUID
+	responseBody.Shuffled = updatedDeck.Shuffled
+	responseBody.Remaining = len(updatedDeck.Cards)
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// HandleCloseDeck closes the deck. A closed deck can still be read, but any
+// attempt to change it fails with 410 Gone.
+func HandleCloseDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (CloseDeckResponseBody, int, error) {
+	var responseBody CloseDeckResponseBody
+	reqUUID := ps.ByName("uuid")
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
+
+	updatedDeck, err := db.CloseByUUID(ctx, dc, reqUUID, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = updatedDeck.UUID
+	responseBody.Closed = updatedDeck.Closed
+	responseBody.Remaining = len(updatedDeck.Cards)
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+func HandleDeleteDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (DeleteDeckResponseBody, int, error) {
+	var responseBody DeleteDeckResponseBody
+	reqUUID := ps.ByName("uuid")
+
+	err := dc.DeleteDeckByUUID(ctx, reqUUID)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = reqUUID
+	responseBody.Deleted = true
 	return responseBody, http.StatusOK, nil
 }
+
+// HandlePeekCards returns cards from the top or bottom of the deck without
+// drawing them. Only privileged callers may peek.
+func HandlePeekCards(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (PeekCardsResponseBody, int, error) {
+	var responseBody PeekCardsResponseBody
+	reqUUID := ps.ByName("uuid")
+	if !isPrivileged(ctx) {
+		return responseBody, http.StatusForbidden, ApiError{Message: "Peeking at the deck requires the admin token"}
+	}
+	query := r.URL.Query()
+	n, err := strconv.Atoi(query.Get("count"))
+	if err != nil || n <= 0 {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Number of cards must be specified and be greater than 0"}
+	}
+
+	resultDeck, err := dc.FindDeckByUUID(ctx, reqUUID)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+	peeked, err := deck.Peek(resultDeck.Cards, deck.DrawMode(query.Get("from")), n)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = resultDeck.UUID
+	responseBody.Remaining = len(resultDeck.Cards)
+	responseBody.Cards = peeked.ToDeckJSON()
+	responseBody.Version = resultDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// HandleSeedDeck shuffles a fair deck with the client seed chosen by the
+// players, after the server seed's commitment was published by
+// HandleCreateDeck.
+func HandleSeedDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (SeedDeckResponseBody, int, error) {
+	var (
+		reqBody      SeedDeckRequestBody
+		responseBody SeedDeckResponseBody
+	)
+	reqUUID := ps.ByName("uuid")
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	if reqBody.ClientSeed == "" {
+		return responseBody, http.StatusBadRequest, ApiError{Message: deck.ErrClientSeedMissing.Error()}
+	}
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
+
+	updatedDeck, err := db.SeedFairDeckByUUID(ctx, dc, reqUUID, reqBody.ClientSeed, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = updatedDeck.UUID
+	responseBody.Shuffled = updatedDeck.Shuffled
+	responseBody.Remaining = len(updatedDeck.Cards)
+	responseBody.ServerSeedHash = updatedDeck.Fair.Commitment
+	responseBody.ClientSeed = updatedDeck.Fair.ClientSeed
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// HandleRevealDeck discloses the server seed of a fair deck once every card
+// has been drawn from it and its piles, or it has been closed, so the shuffle
+// can be checked against the server_seed_hash published when the deck was
+// created. Cards still held in piles would be given away by the reveal.
+func HandleRevealDeck(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (RevealDeckResponseBody, int, error) {
+	var responseBody RevealDeckResponseBody
+	reqUUID := ps.ByName("uuid")
+	resultDeck, err := dc.FindDeckByUUID(ctx, reqUUID)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+	if resultDeck.Fair == nil {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Deck was not created with the fair RNG"}
+	}
+	if !resultDeck.Closed && !dealtOut(resultDeck) {
+		return responseBody, http.StatusConflict, ApiError{Message: "The server seed can only be revealed once every card has been drawn from the deck and its piles or the deck is closed"}
+	}
+
+	responseBody.DeckId = resultDeck.UUID
+	responseBody.ServerSeed = resultDeck.Fair.ServerSeed
+	responseBody.ServerSeedHash = resultDeck.Fair.Commitment
+	responseBody.ClientSeed = resultDeck.Fair.ClientSeed
+	responseBody.Unshuffled = resultDeck.Fair.Unshuffled.ToDeckJSON()
+	responseBody.Shuffled = resultDeck.Original.ToDeckJSON()
+	responseBody.Version = resultDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// dealtOut reports whether no card is left in the deck or any of its piles,
+// so revealing the order of the cards gives nothing away.
+func dealtOut(d db.DeckModel) bool {
+	if len(d.Cards) > 0 {
+		return false
+	}
+	for _, pile := range d.Piles {
+		if len(pile) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// deckErrorResponse maps an error returned while reading or updating a deck
+// to the status code and error sent to the client. It returns a nil error if
+// err is nil.
+func deckErrorResponse(err error) (int, error) {
+	switch err.(type) {
+	case nil:
+		return 0, nil
+	case deck.ErrDrawCardsSizeExceeded, deck.ErrInvalidCardCode,
+		deck.ErrUnknownShuffleMethod, deck.ErrInvalidPasses, deck.ErrInvalidCutPosition,
+		deck.ErrUnknownDrawMode:
+		return http.StatusBadRequest, ApiError{Message: err.Error()}
+	case deck.ErrCardNotInDeck, db.ErrCardNotDrawn:
+		return http.StatusNotFound, ApiError{Message: err.Error()}
+	}
+	switch err {
+	case mongo.ErrNoDocuments:
+		return http.StatusNotFound, ApiError{Message: "Deck with this id does not exist"}
+	case db.ErrPileNotFound:
+		return http.StatusNotFound, ApiError{Message: "Pile with this name does not exist"}
+	case db.ErrVersionMismatch:
+		return http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	case db.ErrNotFairDeck:
+		return http.StatusBadRequest, ApiError{Message: "Deck was not created with the fair RNG"}
+	case db.ErrFairDeckNotSeeded:
+		return http.StatusConflict, ApiError{Message: "Deck is waiting for its client seed"}
+	case db.ErrFairDeckSeeded:
+		return http.StatusConflict, ApiError{Message: "Deck already has a client seed"}
+	case db.ErrUpdateConflict:
+		return http.StatusConflict, ApiError{Message: "Deck is being modified by other requests, try again"}
+	case db.ErrDeckClosed:
+		return http.StatusGone, ApiError{Message: "Deck has been closed"}
+	case db.ErrDeckExpired:
+		return http.StatusGone, ApiError{Message: "Deck has expired"}
+	case deck.ErrFairReshuffle, deck.ErrFairRandomDraw:
+		return http.StatusBadRequest, ApiError{Message: err.Error()}
+	}
+	log.Println("Error occurred while accessing document in db.", err)
+	return http.StatusInternalServerError, ApiError{Message: "Internal Server Error"}
+}
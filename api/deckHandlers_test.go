@@ -8,19 +8,21 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/AbhilashJN/cards/database"
 	"github.com/AbhilashJN/cards/deck"
 	"github.com/google/go-cmp/cmp"
 	"github.com/julienschmidt/httprouter"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type mockDeckCRUDOperator struct {
 	mockInsertDeckFn     func(context.Context, database.DeckModel) error
 	mockFindDeckByUUID   func(ctx context.Context, uuid string) (database.DeckModel, error)
-	mockUpdateDeckByUUID func(context.Context, string, bson.D) error
+	mockUpdateDeckByUUID func(context.Context, string, database.DeckMutation) (database.DeckModel, error)
+	mockDeleteDeckByUUID func(context.Context, string) error
+	mockListDecks        func(context.Context, database.ListDecksOpts) (database.DeckPage, error)
 }
 
 func (d *mockDeckCRUDOperator) InsertDeck(ctx context.Context, deckItem database.DeckModel) error {
@@ -31,14 +33,34 @@ func (d *mockDeckCRUDOperator) FindDeckByUUID(ctx context.Context, uuid string)
 	return d.mockFindDeckByUUID(ctx, uuid)
 }
 
-func (d *mockDeckCRUDOperator) UpdateDeckByUUID(ctx context.Context, uuid string, updateQuery bson.D) error {
-	return d.mockUpdateDeckByUUID(ctx, uuid, updateQuery)
+func (d *mockDeckCRUDOperator) UpdateDeckByUUID(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+	return d.mockUpdateDeckByUUID(ctx, uuid, mutate)
+}
+
+func (d *mockDeckCRUDOperator) DeleteDeckByUUID(ctx context.Context, uuid string) error {
+	return d.mockDeleteDeckByUUID(ctx, uuid)
+}
+
+func (d *mockDeckCRUDOperator) ListDecks(ctx context.Context, opts database.ListDecksOpts) (database.DeckPage, error) {
+	return d.mockListDecks(ctx, opts)
+}
+
+func mockUpdateOf(deckItem database.DeckModel) func(context.Context, string, database.DeckMutation) (database.DeckModel, error) {
+	return func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+		if err := mutate(&deckItem); err != nil {
+			return database.DeckModel{}, err
+		}
+		deckItem.Version++
+		return deckItem, nil
+	}
 }
 
 type HandleCreateDeckTest struct {
 	shuffle          bool
 	customDeck       bool
 	wantedCards      []string
+	decks            int
+	includeJokers    bool
 	expectedNumCards int
 }
 
@@ -56,10 +78,14 @@ func TestHandleCreateDeck(t *testing.T) {
 		{shuffle: true, customDeck: false, wantedCards: []string{}, expectedNumCards: 52},
 		{shuffle: false, customDeck: true, wantedCards: []string{"AS", "QS", "2H", "7D", "4C"}, expectedNumCards: 5},
 		{shuffle: true, customDeck: true, wantedCards: []string{"AS", "QS", "2H", "7D", "4C"}, expectedNumCards: 5},
+		{shuffle: true, customDeck: false, wantedCards: []string{}, decks: 8, expectedNumCards: 416},
+		{shuffle: false, customDeck: true, wantedCards: []string{"AS", "QS"}, decks: 3, expectedNumCards: 6},
+		{shuffle: true, customDeck: false, wantedCards: []string{}, includeJokers: true, expectedNumCards: 54},
+		{shuffle: false, customDeck: true, wantedCards: []string{"X1", "X2", "AS"}, expectedNumCards: 3},
 	}
 
 	for _, test := range tests {
-		mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: test.shuffle, CustomDeck: test.customDeck, WantedCards: test.wantedCards})
+		mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: test.shuffle, CustomDeck: test.customDeck, WantedCards: test.wantedCards, Decks: test.decks, IncludeJokers: test.includeJokers})
 		req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
 		responseBody, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
 		if err != nil {
@@ -81,6 +107,76 @@ func TestHandleCreateDeck(t *testing.T) {
 
 }
 
+func TestHandleCreateDeckSeeded(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted []database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = append(inserted, d)
+		return nil
+	}
+
+	seed := int64(20211219)
+	for i := 0; i < 2; i++ {
+		mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: true, Seed: &seed})
+		req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+		responseBody, _, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+		if err != nil {
+			t.Errorf("Failed for seeded deck: expected err to be %v, got %v", nil, err)
+		}
+		if responseBody.Seed == nil || *responseBody.Seed != seed {
+			t.Errorf("Failed for seeded deck: expected seed to be %d, got %v", seed, responseBody.Seed)
+		}
+	}
+	if !cmp.Equal(inserted[0].Cards, inserted[1].Cards) {
+		t.Errorf("Failed for seeded deck: expected same order for same seed, got %v and %v", inserted[0].Cards, inserted[1].Cards)
+	}
+	if inserted[0].Seed == nil || *inserted[0].Seed != seed {
+		t.Errorf("Failed for seeded deck: expected stored seed to be %d, got %v", seed, inserted[0].Seed)
+	}
+}
+
+func TestHandleCreateDeckRNG(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = d
+		return nil
+	}
+
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: true, RNG: "crypto"})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	responseBody, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusCreated || err != nil {
+		t.Errorf("Failed for crypto rng: expected response code %d and no error, got %d and %v", http.StatusCreated, responseCode, err)
+	}
+	if responseBody.RNG != "crypto" || inserted.RNG != deck.RNGCrypto {
+		t.Errorf("Failed for crypto rng: expected rng to be recorded as crypto, got %s in response and %s in db", responseBody.RNG, inserted.RNG)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{Shuffle: true})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	responseBody, _, _ = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if responseBody.RNG != "math" || inserted.RNG != deck.RNGMath {
+		t.Errorf("Failed for default rng: expected rng to be recorded as math, got %s in response and %s in db", responseBody.RNG, inserted.RNG)
+	}
+
+	seed := int64(7)
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{Shuffle: true, RNG: "crypto", Seed: &seed})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "A seed cannot be used with the crypto RNG"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for crypto rng with seed: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for crypto rng with seed: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+}
+
 func TestHandleCreateDeckNoWantedCardsErr(t *testing.T) {
 	mockParams := httprouter.Params{}
 	mockCtx := context.TODO()
@@ -117,6 +213,24 @@ func TestHandleCreateDeckInvalidCardCodeErr(t *testing.T) {
 	}
 }
 
+func TestHandleCreateDeckInvalidNumberOfDecksErr(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		return nil
+	}
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Decks: 11})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "Number of decks must be between 1 and 10, got 11"}
+	_, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for invalid number of decks error case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for invalid number of decks error case: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+}
+
 func TestHandleCreateDeckDbError(t *testing.T) {
 	mockParams := httprouter.Params{}
 	mockCtx := context.TODO()
@@ -164,6 +278,7 @@ func TestHandleGetDeck(t *testing.T) {
 		UUID:     "test-uuid-123",
 		Shuffled: false,
 		Cards:    deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}},
+		Version:  4,
 	}
 	mdc := mockDeckCRUDOperator{}
 	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
@@ -175,7 +290,7 @@ func TestHandleGetDeck(t *testing.T) {
 		DeckId:    "test-uuid-123",
 		Shuffled:  false,
 		Remaining: 2,
-		Cards:     deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(),
+		Version:   4,
 	}
 	response, responseCode, err := HandleGetDeck(req, mockParams, &mdc, mockCtx)
 	if !cmp.Equal(response, expectedResponse) {
@@ -188,6 +303,37 @@ func TestHandleGetDeck(t *testing.T) {
 		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
 	}
 
+	req = httptest.NewRequest("GET", "/deck/test-uuid-123?reveal=true", bytes.NewReader([]byte{}))
+	expectedResponse.Cards = deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON()
+	response, responseCode, err = HandleGetDeck(req, mockParams, &mdc, WithPrivilege(mockCtx, true))
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for reveal case: expected respnonse to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for reveal case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for reveal case: expected error to be %v, got %v", nil, err)
+	}
+
+	expectedErr := ApiError{Message: "Revealing the cards requires the admin token"}
+	_, responseCode, err = HandleGetDeck(req, mockParams, &mdc, WithPrivilege(mockCtx, false))
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for unprivileged reveal case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusForbidden {
+		t.Errorf("Failed for unprivileged reveal case: expected response code to be %d, got %d", http.StatusForbidden, responseCode)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-123?reveal=maybe", bytes.NewReader([]byte{}))
+	expectedErr = ApiError{Message: "reveal must be true or false"}
+	_, responseCode, err = HandleGetDeck(req, mockParams, &mdc, WithPrivilege(mockCtx, true))
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for malformed reveal case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for malformed reveal case: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
 }
 
 func TestHandleGetDeckNotFound(t *testing.T) {
@@ -209,6 +355,25 @@ func TestHandleGetDeckNotFound(t *testing.T) {
 	}
 }
 
+func TestHandleGetDeckExpired(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return database.DeckModel{}, database.ErrDeckExpired
+	}
+
+	req := httptest.NewRequest("GET", "/deck/test-uuid-123", bytes.NewReader([]byte{}))
+	expectedErr := ApiError{Message: "Deck has expired"}
+	_, responseCode, err := HandleGetDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for deck expired case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusGone {
+		t.Errorf("Failed for deck expired case: expected response code to be %d, got %d", http.StatusGone, responseCode)
+	}
+}
+
 func TestHandleGetDeckDbError(t *testing.T) {
 	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
 	mockCtx := context.TODO()
@@ -241,16 +406,12 @@ func TestHandleDrawCards(t *testing.T) {
 		},
 	}
 	mdc := mockDeckCRUDOperator{}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
-		return mockResult, nil
-	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return nil
-	}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
 	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
 	expectedResponse := DrawCardsResponseBody{
-		Cards: deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(),
+		Cards:   deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(),
+		Version: 1,
 	}
 	response, responseCode, err := HandleDrawCards(req, mockParams, &mdc, mockCtx)
 	if !cmp.Equal(response, expectedResponse) {
@@ -264,6 +425,77 @@ func TestHandleDrawCards(t *testing.T) {
 	}
 }
 
+type HandleDrawCardsIfMatchTest struct {
+	ifMatch      string
+	expectedCode int
+}
+
+func TestHandleDrawCardsIfMatch(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mockResult := database.DeckModel{
+		UUID: "test-uuid-123",
+		Cards: deck.Deck{
+			{Value: deck.Ace, Suit: deck.Spades},
+			{Value: deck.Three, Suit: deck.Clubs},
+			{Value: deck.Nine, Suit: deck.Hearts},
+		},
+		Version: 3,
+	}
+	mdc := mockDeckCRUDOperator{}
+
+	tests := []HandleDrawCardsIfMatchTest{
+		{ifMatch: "", expectedCode: http.StatusOK},
+		{ifMatch: "*", expectedCode: http.StatusOK},
+		{ifMatch: `"3"`, expectedCode: http.StatusOK},
+		{ifMatch: `"2"`, expectedCode: http.StatusPreconditionFailed},
+		{ifMatch: `W/"3"`, expectedCode: http.StatusPreconditionFailed},
+		{ifMatch: `"abc"`, expectedCode: http.StatusPreconditionFailed},
+		{ifMatch: `"0"`, expectedCode: http.StatusPreconditionFailed},
+		{ifMatch: `"-1"`, expectedCode: http.StatusPreconditionFailed},
+	}
+
+	for _, test := range tests {
+		mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
+		mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 1})
+		req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
+		if test.ifMatch != "" {
+			req.Header.Set("If-Match", test.ifMatch)
+		}
+		response, responseCode, err := HandleDrawCards(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for If-Match '%s': expected response code to be %d, got %d", test.ifMatch, test.expectedCode, responseCode)
+		}
+		if test.expectedCode == http.StatusOK && response.Version != 4 {
+			t.Errorf("Failed for If-Match '%s': expected version to be %d, got %d", test.ifMatch, 4, response.Version)
+		}
+		if test.expectedCode == http.StatusPreconditionFailed && err == nil {
+			t.Errorf("Failed for If-Match '%s': expected an error, got %v", test.ifMatch, err)
+		}
+	}
+}
+
+func TestHandleDrawCardsIfMatchUnversionedDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	// Decks stored before versions were introduced are at version 0.
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(database.DeckModel{
+		UUID:  "test-uuid-123",
+		Cards: deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}},
+	})
+	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 1})
+	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
+	req.Header.Set("If-Match", ETag(0))
+	response, responseCode, err := HandleDrawCards(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusOK || err != nil {
+		t.Errorf("Failed for unversioned deck: expected response code to be %d, got %d and error %v", http.StatusOK, responseCode, err)
+	}
+	if response.Version != 1 {
+		t.Errorf("Failed for unversioned deck: expected version to be %d, got %d", 1, response.Version)
+	}
+}
+
 func TestHandleDrawCardsSizeExceededError(t *testing.T) {
 	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-1234"}}
 	mockCtx := context.TODO()
@@ -277,12 +509,7 @@ func TestHandleDrawCardsSizeExceededError(t *testing.T) {
 			{Value: deck.Nine, Suit: deck.Hearts},
 		},
 	}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
-		return mockResult, nil
-	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return errors.New("test update error")
-	}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
 	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 4})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
 	expectedErr := ApiError{Message: "Requested number of cards is greater than the cards remaining in the deck"}
@@ -308,12 +535,7 @@ func TestHandleDrawCardsNumberNotGivenError(t *testing.T) {
 			{Value: deck.Nine, Suit: deck.Hearts},
 		},
 	}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
-		return mockResult, nil
-	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return errors.New("test update error")
-	}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
 	mockBody, _ := json.Marshal(struct{}{})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
 	expectedErr := ApiError{Message: "Number of cards must be specified and be greater than 0"}
@@ -330,12 +552,9 @@ func TestHandleDrawCardsDeckNotFound(t *testing.T) {
 	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
 	mockCtx := context.TODO()
 	mdc := mockDeckCRUDOperator{}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
 		return database.DeckModel{}, mongo.ErrNoDocuments
 	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return nil
-	}
 	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
 	expectedErr := ApiError{Message: "Deck with this id does not exist"}
@@ -348,25 +567,22 @@ func TestHandleDrawCardsDeckNotFound(t *testing.T) {
 	}
 }
 
-func TestHandleDrawCardsDbReadError(t *testing.T) {
+func TestHandleDrawCardsDbConflictError(t *testing.T) {
 	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
 	mockCtx := context.TODO()
 	mdc := mockDeckCRUDOperator{}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
-		return database.DeckModel{}, errors.New("test error 456")
-	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return nil
+	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+		return database.DeckModel{}, database.ErrUpdateConflict
 	}
 	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
-	expectedErr := ApiError{Message: "Internal Server Error"}
+	expectedErr := ApiError{Message: "Deck is being modified by other requests, try again"}
 	_, responseCode, err := HandleDrawCards(req, mockParams, &mdc, mockCtx)
 	if !cmp.Equal(err, expectedErr) {
-		t.Errorf("Failed for db read error case: expected error to be %v, got %v", expectedErr, err)
+		t.Errorf("Failed for db conflict error case: expected error to be %v, got %v", expectedErr, err)
 	}
-	if responseCode != http.StatusInternalServerError {
-		t.Errorf("Failed for db read error case: expected response code to be %d, got %d", http.StatusInternalServerError, responseCode)
+	if responseCode != http.StatusConflict {
+		t.Errorf("Failed for db conflict error case: expected response code to be %d, got %d", http.StatusConflict, responseCode)
 	}
 }
 
@@ -383,11 +599,12 @@ func TestHandleDrawCardsDbUpdateError(t *testing.T) {
 			{Value: deck.Nine, Suit: deck.Hearts},
 		},
 	}
-	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
-		return mockResult, nil
-	}
-	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, filterQuery bson.D) error {
-		return errors.New("test update error")
+	mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+		deckItem := mockResult
+		if err := mutate(&deckItem); err != nil {
+			return database.DeckModel{}, err
+		}
+		return database.DeckModel{}, errors.New("test update error")
 	}
 	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
@@ -400,3 +617,961 @@ func TestHandleDrawCardsDbUpdateError(t *testing.T) {
 		t.Errorf("Failed for db update error case: expected response code to be %d, got %d", http.StatusInternalServerError, responseCode)
 	}
 }
+
+func TestHandleReturnCards(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mockResult := database.DeckModel{
+		UUID:  "test-uuid-123",
+		Cards: deck.Deck{{Value: deck.Nine, Suit: deck.Hearts}},
+		Drawn: deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}},
+	}
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
+
+	mockBody, _ := json.Marshal(ReturnCardsRequestBody{Cards: []string{"3C"}})
+	req := httptest.NewRequest("POST", "/deck/test-uuid-123/return", bytes.NewReader(mockBody))
+	expectedResponse := ReturnCardsResponseBody{
+		DeckId:    "test-uuid-123",
+		Remaining: 2,
+		Cards:     deck.Deck{{Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(),
+		Version:   1,
+	}
+	response, responseCode, err := HandleReturnCards(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
+	req = httptest.NewRequest("POST", "/deck/test-uuid-123/return", bytes.NewReader([]byte{}))
+	response, responseCode, err = HandleReturnCards(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusOK || response.Remaining != 3 {
+		t.Errorf("Failed for return all case: expected response code %d and %d remaining, got %d and %d (%v)", http.StatusOK, 3, responseCode, response.Remaining, err)
+	}
+
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
+	mockBody, _ = json.Marshal(ReturnCardsRequestBody{Cards: []string{"9H"}})
+	req = httptest.NewRequest("POST", "/deck/test-uuid-123/return", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "Card 9H has not been drawn from the deck"}
+	_, responseCode, err = HandleReturnCards(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for card not drawn case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusNotFound {
+		t.Errorf("Failed for card not drawn case: expected response code to be %d, got %d", http.StatusNotFound, responseCode)
+	}
+}
+
+func TestHandleShuffleDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mockResult := database.DeckModel{
+		UUID:  "test-uuid-123",
+		Cards: deck.Deck{{Value: deck.Nine, Suit: deck.Hearts}, {Value: deck.Ace, Suit: deck.Spades}},
+		Drawn: deck.Deck{{Value: deck.Three, Suit: deck.Clubs}},
+	}
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(mockResult)
+
+	req := httptest.NewRequest("POST", "/deck/test-uuid-123/shuffle", bytes.NewReader([]byte{}))
+	expectedResponse := ShuffleDeckResponseBody{
+		DeckId:    "test-uuid-123",
+		Shuffled:  true,
+		Remaining: 2,
+		Version:   1,
+	}
+	response, responseCode, err := HandleShuffleDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+}
+
+func TestHandleCreateDeckFair(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = d
+		return nil
+	}
+
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{RNG: "fair"})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	responseBody, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusCreated || err != nil {
+		t.Fatalf("Failed for fair rng: expected response code %d and no error, got %d and %v", http.StatusCreated, responseCode, err)
+	}
+	if responseBody.Shuffled || inserted.Shuffled {
+		t.Errorf("Failed for fair rng: expected deck not to be shuffled before the client seed is sent")
+	}
+	if inserted.Fair == nil {
+		t.Fatalf("Failed for fair rng: expected fair shuffle to be stored")
+	}
+	if responseBody.ServerSeedHash != deck.Commitment(inserted.Fair.ServerSeed) {
+		t.Errorf("Failed for fair rng: expected server seed hash %s, got %s", deck.Commitment(inserted.Fair.ServerSeed), responseBody.ServerSeedHash)
+	}
+	if responseBody.ClientSeed != "" || inserted.Fair.ClientSeed != "" {
+		t.Errorf("Failed for fair rng: expected no client seed, got %s", responseBody.ClientSeed)
+	}
+	if !cmp.Equal(inserted.Fair.Unshuffled, inserted.Cards) {
+		t.Errorf("Failed for fair rng: expected the unshuffled cards to be stored")
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{RNG: "fair", ClientSeed: "player-one"})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "The client seed of a fair deck must be sent to /deck/{deck_id}/seed once the deck is created"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for fair rng with client seed: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for fair rng with client seed: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+}
+
+func TestHandleSeedDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	unshuffled, _ := deck.New(&deck.NewDeckOpts{})
+	serverSeed := deck.NewServerSeed()
+	fairDeck := func(clientSeed string) database.DeckModel {
+		return database.DeckModel{
+			UUID:     "test-uuid-123",
+			Cards:    append(deck.Deck{}, unshuffled...),
+			Original: append(deck.Deck{}, unshuffled...),
+			RNG:      deck.RNGFair,
+			Fair:     &database.FairShuffleModel{ServerSeed: serverSeed, Commitment: deck.Commitment(serverSeed), ClientSeed: clientSeed, Unshuffled: unshuffled},
+			Version:  1,
+		}
+	}
+
+	tests := []struct {
+		name         string
+		deckItem     database.DeckModel
+		clientSeed   string
+		expectedCode int
+		expectedErr  error
+	}{
+		{name: "unseeded", deckItem: fairDeck(""), clientSeed: "player-one", expectedCode: http.StatusOK},
+		{name: "seeded", deckItem: fairDeck("player-one"), clientSeed: "player-two", expectedCode: http.StatusConflict, expectedErr: ApiError{Message: "Deck already has a client seed"}},
+		{name: "not fair", deckItem: database.DeckModel{UUID: "test-uuid-123", Cards: unshuffled, Version: 1}, clientSeed: "player-one", expectedCode: http.StatusBadRequest, expectedErr: ApiError{Message: "Deck was not created with the fair RNG"}},
+		{name: "no client seed", deckItem: fairDeck(""), expectedCode: http.StatusBadRequest, expectedErr: ApiError{Message: "A client seed must be provided for the fair RNG"}},
+	}
+	for _, test := range tests {
+		var updated database.DeckModel
+		mdc := mockDeckCRUDOperator{}
+		update := mockUpdateOf(test.deckItem)
+		mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+			var err error
+			updated, err = update(ctx, uuid, mutate)
+			return updated, err
+		}
+		mockBody, _ := json.Marshal(SeedDeckRequestBody{ClientSeed: test.clientSeed})
+		req := httptest.NewRequest("POST", "/deck/test-uuid-123/seed", bytes.NewReader(mockBody))
+		response, responseCode, err := HandleSeedDeck(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if test.expectedErr != nil {
+			continue
+		}
+		expected := SeedDeckResponseBody{DeckId: "test-uuid-123", Shuffled: true, Remaining: 52, ServerSeedHash: deck.Commitment(serverSeed), ClientSeed: test.clientSeed, Version: 2}
+		if !cmp.Equal(response, expected) {
+			t.Errorf("Failed for %s case: expected response to be %v, got %v", test.name, expected, response)
+		}
+		if !deck.VerifyFairShuffle(serverSeed, response.ServerSeedHash, test.clientSeed, unshuffled, updated.Original) || !cmp.Equal(updated.Cards, updated.Original) {
+			t.Errorf("Failed for %s case: expected the stored shuffle to verify", test.name)
+		}
+	}
+}
+
+func TestHandleShuffleDeckFair(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(database.DeckModel{
+		UUID:  "test-uuid-123",
+		Cards: deck.Deck{{Value: deck.Nine, Suit: deck.Hearts}, {Value: deck.Ace, Suit: deck.Spades}},
+		RNG:   deck.RNGFair,
+	})
+
+	req := httptest.NewRequest("POST", "/deck/test-uuid-123/shuffle", bytes.NewReader([]byte{}))
+	expectedErr := ApiError{Message: "Provably fair decks can only be shuffled when they are created"}
+	_, responseCode, err := HandleShuffleDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for fair deck: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for fair deck: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+}
+
+type HandleRevealDeckTest struct {
+	name         string
+	deckItem     database.DeckModel
+	expectedCode int
+	expectedErr  error
+}
+
+func TestHandleRevealDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	unshuffled := deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Two, Suit: deck.Spades}}
+	shuffled := deck.Deck{{Value: deck.Two, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Spades}}
+	fair := &database.FairShuffleModel{ServerSeed: "server", Commitment: deck.Commitment("server"), ClientSeed: "client", Unshuffled: unshuffled}
+
+	tests := []HandleRevealDeckTest{
+		{
+			name:         "exhausted",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Original: shuffled, Drawn: shuffled, RNG: deck.RNGFair, Fair: fair, Version: 3},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "cards remaining",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Cards: shuffled[1:], Original: shuffled, Drawn: shuffled[:1], RNG: deck.RNGFair, Fair: fair, Version: 2},
+			expectedCode: http.StatusConflict,
+			expectedErr:  ApiError{Message: "The server seed can only be revealed once every card has been drawn from the deck and its piles or the deck is closed"},
+		},
+		{
+			name:         "cards in a pile",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Original: shuffled, Piles: map[string]deck.Deck{"player1": shuffled[1:]}, Drawn: shuffled[:1], RNG: deck.RNGFair, Fair: fair, Version: 3},
+			expectedCode: http.StatusConflict,
+			expectedErr:  ApiError{Message: "The server seed can only be revealed once every card has been drawn from the deck and its piles or the deck is closed"},
+		},
+		{
+			name:         "piles emptied",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Original: shuffled, Piles: map[string]deck.Deck{"player1": {}}, Drawn: shuffled, RNG: deck.RNGFair, Fair: fair, Version: 3},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "closed",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Cards: shuffled[1:], Original: shuffled, Drawn: shuffled[:1], RNG: deck.RNGFair, Fair: fair, Closed: true, Version: 3},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "not fair",
+			deckItem:     database.DeckModel{UUID: "test-uuid-123", Original: shuffled, Drawn: shuffled, RNG: deck.RNGMath, Version: 3},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Deck was not created with the fair RNG"},
+		},
+	}
+
+	for _, test := range tests {
+		deckItem := test.deckItem
+		mdc := mockDeckCRUDOperator{}
+		mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+			return deckItem, nil
+		}
+		req := httptest.NewRequest("GET", "/deck/test-uuid-123/reveal", bytes.NewReader([]byte{}))
+		response, responseCode, err := HandleRevealDeck(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		expectedResponse := RevealDeckResponseBody{
+			DeckId:         "test-uuid-123",
+			ServerSeed:     "server",
+			ServerSeedHash: deck.Commitment("server"),
+			ClientSeed:     "client",
+			Unshuffled:     unshuffled.ToDeckJSON(),
+			Shuffled:       shuffled.ToDeckJSON(),
+			Version:        3,
+		}
+		if !cmp.Equal(response, expectedResponse) {
+			t.Errorf("Failed for %s case: expected response to be %v, got %v", test.name, expectedResponse, response)
+		}
+	}
+}
+
+type HandleShuffleDeckMethodTest struct {
+	reqBody       ShuffleDeckRequestBody
+	expectedCode  int
+	expectedErr   error
+	expectedCards deck.Deck
+}
+
+func TestHandleShuffleDeckMethods(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	cards := deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Two, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Spades}}
+	position := 1
+	badPosition := 4
+
+	tests := []HandleShuffleDeckMethodTest{
+		{
+			reqBody:       ShuffleDeckRequestBody{ShuffleMethod: "cut", Position: &position},
+			expectedCode:  http.StatusOK,
+			expectedCards: deck.Deck{cards[1], cards[2], cards[0]},
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "riffle", Passes: 7},
+			expectedCode: http.StatusOK,
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "riffle", Position: &position},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "A position can only be given with the cut shuffle method"},
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "cut", Position: &badPosition},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Cut position must be between 0 and 3, got 4"},
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "riffle", Passes: deck.MaxPasses + 1},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Number of passes must be between 1 and 100, got 101"},
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "overhand", Passes: deck.MaxOverhandPasses + 1},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Number of passes must be between 1 and 10000, got 10001"},
+		},
+		{
+			reqBody:      ShuffleDeckRequestBody{ShuffleMethod: "pharaoh"},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Shuffle method pharaoh is unknown"},
+		},
+	}
+
+	for _, test := range tests {
+		mdc := mockDeckCRUDOperator{}
+		mdc.mockUpdateDeckByUUID = mockUpdateOf(database.DeckModel{UUID: "test-uuid-123", Cards: append(deck.Deck{}, cards...)})
+		var updated database.DeckModel
+		update := mdc.mockUpdateDeckByUUID
+		mdc.mockUpdateDeckByUUID = func(ctx context.Context, uuid string, mutate database.DeckMutation) (database.DeckModel, error) {
+			d, err := update(ctx, uuid, mutate)
+			updated = d
+			return d, err
+		}
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("POST", "/deck/test-uuid-123/shuffle", bytes.NewReader(mockBody))
+		_, responseCode, err := HandleShuffleDeck(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %+v: expected response code to be %d, got %d", test.reqBody, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %+v: expected error to be %v, got %v", test.reqBody, test.expectedErr, err)
+		}
+		if test.expectedCards != nil && !cmp.Equal(updated.Cards, test.expectedCards) {
+			t.Errorf("Failed for %+v: expected cards to be %v, got %v", test.reqBody, test.expectedCards, updated.Cards)
+		}
+	}
+}
+
+func TestHandleCreateDeckShuffleMethod(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		return nil
+	}
+
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: true, ShuffleMethod: "overhand", Passes: 10})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	responseBody, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusCreated || err != nil {
+		t.Errorf("Failed for overhand shuffle: expected response code %d and no error, got %d and %v", http.StatusCreated, responseCode, err)
+	}
+	if responseBody.Remaining != 52 {
+		t.Errorf("Failed for overhand shuffle: expected remaining cards to be %d, got %d", 52, responseBody.Remaining)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{Shuffle: true, ShuffleMethod: "pharaoh"})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "Shuffle method pharaoh is unknown"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for unknown shuffle method: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for unknown shuffle method: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{ShuffleMethod: "bogus", Passes: -5})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr = ApiError{Message: "Shuffle method bogus is unknown"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) || responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for unknown shuffle method without shuffle: expected response code %d and error %v, got %d and %v", http.StatusBadRequest, expectedErr, responseCode, err)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{ShuffleMethod: "riffle", Passes: -5})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr = ApiError{Message: "Number of passes must be between 1 and 100, got -5"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) || responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for invalid passes without shuffle: expected response code %d and error %v, got %d and %v", http.StatusBadRequest, expectedErr, responseCode, err)
+	}
+}
+
+type HandleDrawCardsModeTest struct {
+	reqBody       DrawCardsRequestBody
+	rng           deck.RNG
+	expectedCode  int
+	expectedErr   error
+	expectedCards deck.DeckJSON
+}
+
+func TestHandleDrawCardsModes(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	cards := deck.Deck{
+		{Value: deck.Ace, Suit: deck.Spades},
+		{Value: deck.Three, Suit: deck.Clubs},
+		{Value: deck.Nine, Suit: deck.Hearts},
+	}
+
+	tests := []HandleDrawCardsModeTest{
+		{
+			reqBody:       DrawCardsRequestBody{NumberOfCards: 2, From: "bottom"},
+			expectedCode:  http.StatusOK,
+			expectedCards: deck.Deck{cards[2], cards[1]}.ToDeckJSON(),
+		},
+		{
+			reqBody:       DrawCardsRequestBody{Cards: []string{"3C"}},
+			expectedCode:  http.StatusOK,
+			expectedCards: deck.Deck{cards[1]}.ToDeckJSON(),
+		},
+		{
+			reqBody:      DrawCardsRequestBody{NumberOfCards: 3, From: "random"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			reqBody:      DrawCardsRequestBody{Cards: []string{"KD"}},
+			expectedCode: http.StatusNotFound,
+			expectedErr:  ApiError{Message: "Card KD is not in the deck"},
+		},
+		{
+			reqBody:      DrawCardsRequestBody{Cards: []string{"3C"}, NumberOfCards: 1},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "A list of cards cannot be combined with numberOfCards or from"},
+		},
+		{
+			reqBody:      DrawCardsRequestBody{NumberOfCards: 1, From: "middle"},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Draw mode middle is unknown"},
+		},
+		{
+			reqBody:      DrawCardsRequestBody{NumberOfCards: 1, From: "random"},
+			rng:          deck.RNGFair,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Cards cannot be drawn from random positions of a provably fair deck"},
+		},
+	}
+
+	for _, test := range tests {
+		mdc := mockDeckCRUDOperator{}
+		mdc.mockUpdateDeckByUUID = mockUpdateOf(database.DeckModel{UUID: "test-uuid-123", Cards: append(deck.Deck{}, cards...), RNG: test.rng})
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
+		response, responseCode, err := HandleDrawCards(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %+v: expected response code to be %d, got %d", test.reqBody, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %+v: expected error to be %v, got %v", test.reqBody, test.expectedErr, err)
+		}
+		if test.expectedCards != nil && !cmp.Equal(response.Cards, test.expectedCards) {
+			t.Errorf("Failed for %+v: expected cards to be %v, got %v", test.reqBody, test.expectedCards, response.Cards)
+		}
+		if err == nil && test.expectedCards == nil && len(response.Cards) != test.reqBody.NumberOfCards {
+			t.Errorf("Failed for %+v: expected %d cards, got %v", test.reqBody, test.reqBody.NumberOfCards, response.Cards)
+		}
+	}
+}
+
+type HandlePeekCardsTest struct {
+	query         string
+	privileged    bool
+	expectedCode  int
+	expectedErr   error
+	expectedCards deck.DeckJSON
+}
+
+func TestHandlePeekCards(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	cards := deck.Deck{
+		{Value: deck.Ace, Suit: deck.Spades},
+		{Value: deck.Three, Suit: deck.Clubs},
+		{Value: deck.Nine, Suit: deck.Hearts},
+	}
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return database.DeckModel{UUID: "test-uuid-123", Cards: cards, Version: 4}, nil
+	}
+
+	tests := []HandlePeekCardsTest{
+		{query: "?count=2", privileged: true, expectedCode: http.StatusOK, expectedCards: cards[:2].ToDeckJSON()},
+		{query: "?count=1&from=bottom", privileged: true, expectedCode: http.StatusOK, expectedCards: cards[2:].ToDeckJSON()},
+		{query: "?count=2", privileged: false, expectedCode: http.StatusForbidden, expectedErr: ApiError{Message: "Peeking at the deck requires the admin token"}},
+		{query: "", privileged: true, expectedCode: http.StatusBadRequest, expectedErr: ApiError{Message: "Number of cards must be specified and be greater than 0"}},
+		{query: "?count=4", privileged: true, expectedCode: http.StatusBadRequest, expectedErr: ApiError{Message: "Requested number of cards is greater than the cards remaining in the deck"}},
+		{query: "?count=1&from=random", privileged: true, expectedCode: http.StatusBadRequest, expectedErr: ApiError{Message: "Draw mode random is unknown"}},
+	}
+
+	for _, test := range tests {
+		mockCtx := WithPrivilege(context.TODO(), test.privileged)
+		req := httptest.NewRequest("GET", "/deck/test-uuid-123/peek"+test.query, nil)
+		response, responseCode, err := HandlePeekCards(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for query '%s': expected response code to be %d, got %d", test.query, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for query '%s': expected error to be %v, got %v", test.query, test.expectedErr, err)
+		}
+		if err == nil && !cmp.Equal(response.Cards, test.expectedCards) {
+			t.Errorf("Failed for query '%s': expected cards to be %v, got %v", test.query, test.expectedCards, response.Cards)
+		}
+		if err == nil && response.Remaining != len(cards) {
+			t.Errorf("Failed for query '%s': expected remaining to be %d, got %d", test.query, len(cards), response.Remaining)
+		}
+	}
+}
+
+func TestHasAdminToken(t *testing.T) {
+	req := httptest.NewRequest("GET", "/deck/test-uuid-123/peek", nil)
+	if HasAdminToken(req, "") {
+		t.Errorf("Failed for no admin token: expected request not to be privileged")
+	}
+	req.Header.Set("Authorization", "Bearer ")
+	if HasAdminToken(req, "") {
+		t.Errorf("Failed for no admin token and an empty bearer token: expected request not to be privileged")
+	}
+	req.Header.Del("Authorization")
+	if HasAdminToken(req, "secret") {
+		t.Errorf("Failed for missing header: expected request not to be privileged")
+	}
+	req.Header.Set("Authorization", "Bearer wrong")
+	if HasAdminToken(req, "secret") {
+		t.Errorf("Failed for wrong token: expected request not to be privileged")
+	}
+	req.Header.Set("Authorization", "secret")
+	if HasAdminToken(req, "secret") {
+		t.Errorf("Failed for token without the bearer scheme: expected request not to be privileged")
+	}
+	req.Header.Set("Authorization", "Basic secret")
+	if HasAdminToken(req, "secret") {
+		t.Errorf("Failed for token with another scheme: expected request not to be privileged")
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	if !HasAdminToken(req, "secret") {
+		t.Errorf("Failed for right token: expected request to be privileged")
+	}
+}
+
+func TestHandleCreateDeckMetadata(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = d
+		return nil
+	}
+
+	seed := int64(3)
+	before := time.Now()
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Shuffle: true, CustomDeck: true, WantedCards: []string{"as", "10H"}, Decks: 2, Seed: &seed, ShuffleMethod: "riffle", Passes: 7})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	expected := database.DeckModel{
+		Shuffled:      true,
+		Seed:          &seed,
+		RNG:           deck.RNGMath,
+		ShuffleMethod: deck.ShuffleRiffle,
+		Passes:        7,
+		WantedCards:   []string{"AS", "0H"},
+		Decks:         2,
+		OriginalSize:  4,
+	}
+	got := database.DeckModel{
+		Shuffled:      inserted.Shuffled,
+		Seed:          inserted.Seed,
+		RNG:           inserted.RNG,
+		ShuffleMethod: inserted.ShuffleMethod,
+		Passes:        inserted.Passes,
+		WantedCards:   inserted.WantedCards,
+		Decks:         inserted.Decks,
+		OriginalSize:  inserted.OriginalSize,
+	}
+	if !cmp.Equal(got, expected) {
+		t.Errorf("Failed for shuffled custom deck: expected metadata %v, got %v", expected, got)
+	}
+	if inserted.CreatedAt.Before(before.Add(-time.Second)) || inserted.CreatedAt.After(time.Now()) {
+		t.Errorf("Failed for shuffled custom deck: expected created at to be now, got %v", inserted.CreatedAt)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if inserted.Shuffled || inserted.ShuffleMethod != "" || inserted.Decks != 1 || inserted.OriginalSize != 52 || inserted.WantedCards != nil {
+		t.Errorf("Failed for default deck: expected unshuffled single deck of 52 cards, got %v", inserted)
+	}
+}
+
+func TestHandleGetDeckMetadata(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	seed := int64(3)
+	createdAt := time.Date(2021, 12, 19, 10, 0, 0, 0, time.UTC)
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return database.DeckModel{
+			UUID:          "test-uuid-123",
+			Cards:         deck.Deck{{Value: deck.Ace, Suit: deck.Spades}},
+			Shuffled:      true,
+			Seed:          &seed,
+			RNG:           deck.RNGMath,
+			ShuffleMethod: deck.ShuffleRiffle,
+			Passes:        7,
+			WantedCards:   []string{"AS", "0H"},
+			Decks:         2,
+			OriginalSize:  4,
+			CreatedAt:     createdAt,
+			Version:       3,
+		}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/deck/test-uuid-123", bytes.NewReader([]byte{}))
+	expectedResponse := GetDeckResponseBody{
+		DeckId:        "test-uuid-123",
+		Shuffled:      true,
+		Remaining:     1,
+		ShuffleMethod: "riffle",
+		Passes:        7,
+		Seed:          &seed,
+		RNG:           "math",
+		CustomDeck:    true,
+		WantedCards:   []string{"AS", "0H"},
+		Decks:         2,
+		OriginalSize:  4,
+		CreatedAt:     createdAt,
+		Version:       3,
+	}
+	response, _, err := HandleGetDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for metadata case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if err != nil {
+		t.Errorf("Failed for metadata case: expected error to be %v, got %v", nil, err)
+	}
+}
+
+func TestHandleCloseDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(database.DeckModel{
+		UUID:    "test-uuid-123",
+		Cards:   deck.Deck{{Value: deck.Nine, Suit: deck.Hearts}, {Value: deck.Ace, Suit: deck.Spades}},
+		Version: 2,
+	})
+
+	req := httptest.NewRequest("POST", "/deck/test-uuid-123/close", nil)
+	expectedResponse := CloseDeckResponseBody{
+		DeckId:    "test-uuid-123",
+		Closed:    true,
+		Remaining: 2,
+		Version:   3,
+	}
+	response, responseCode, err := HandleCloseDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	expectedErr := ApiError{Message: "Deck has been closed"}
+	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 1})
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-123", bytes.NewReader(mockBody))
+	_, responseCode, err = HandleDrawCards(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for draw from closed deck: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusGone {
+		t.Errorf("Failed for draw from closed deck: expected response code to be %d, got %d", http.StatusGone, responseCode)
+	}
+
+	req = httptest.NewRequest("POST", "/deck/test-uuid-123/close", nil)
+	_, responseCode, _ = HandleCloseDeck(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusGone {
+		t.Errorf("Failed for closing a closed deck: expected response code to be %d, got %d", http.StatusGone, responseCode)
+	}
+}
+
+func TestHandleDeleteDeck(t *testing.T) {
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}}
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockDeleteDeckByUUID = func(ctx context.Context, uuid string) error {
+		return nil
+	}
+
+	req := httptest.NewRequest("DELETE", "/deck/test-uuid-123", nil)
+	expectedResponse := DeleteDeckResponseBody{DeckId: "test-uuid-123", Deleted: true}
+	response, responseCode, err := HandleDeleteDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	mdc.mockDeleteDeckByUUID = func(ctx context.Context, uuid string) error {
+		return mongo.ErrNoDocuments
+	}
+	expectedErr := ApiError{Message: "Deck with this id does not exist"}
+	_, responseCode, err = HandleDeleteDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for deck not found case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusNotFound {
+		t.Errorf("Failed for deck not found case: expected response code to be %d, got %d", http.StatusNotFound, responseCode)
+	}
+}
+
+func TestHandleListDecks(t *testing.T) {
+	mockCtx := WithPrivilege(context.TODO(), true)
+	createdAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	var listedOpts database.ListDecksOpts
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockListDecks = func(ctx context.Context, opts database.ListDecksOpts) (database.DeckPage, error) {
+		listedOpts = opts
+		if opts.After == "bad" {
+			return database.DeckPage{}, database.ErrInvalidCursor
+		}
+		return database.DeckPage{
+			Decks: []database.DeckModel{{
+				UUID:      "test-uuid-123",
+				Cards:     deck.Deck{{Value: deck.Ace, Suit: deck.Spades}},
+				CreatedAt: createdAt,
+				Owner:     "table-7",
+				Tags:      []string{"blackjack"},
+				Version:   3,
+			}},
+			NextCursor: "next",
+		}, nil
+	}
+
+	req := httptest.NewRequest("GET", "/deck?owner=table-7&tag=blackjack&created_after=2021-05-01T00:00:00Z&remaining_below=10&limit=1&cursor=abc", nil)
+	remainingBelow := 10
+	expectedOpts := database.ListDecksOpts{
+		Filter: database.DeckFilter{
+			CreatedAfter:   time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+			RemainingBelow: &remainingBelow,
+			Owner:          "table-7",
+			Tag:            "blackjack",
+		},
+		Limit: 1,
+		After: "abc",
+	}
+	expectedResponse := ListDecksResponseBody{
+		Decks: []GetDeckResponseBody{{
+			DeckId:    "test-uuid-123",
+			Remaining: 1,
+			CreatedAt: createdAt,
+			Owner:     "table-7",
+			Tags:      []string{"blackjack"},
+			Version:   3,
+		}},
+		NextCursor: "next",
+	}
+	response, responseCode, err := HandleListDecks(req, nil, &mdc, mockCtx)
+	if !cmp.Equal(listedOpts, expectedOpts) {
+		t.Errorf("Failed for success case: expected list options to be %v, got %v", expectedOpts, listedOpts)
+	}
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	req = httptest.NewRequest("GET", "/deck", nil)
+	HandleListDecks(req, nil, &mdc, mockCtx)
+	if listedOpts.Limit != defaultListLimit {
+		t.Errorf("Failed for default limit case: expected limit to be %d, got %d", defaultListLimit, listedOpts.Limit)
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		ctx          context.Context
+		expectedCode int
+		expectedErr  error
+	}{
+		{
+			name:         "unprivileged",
+			query:        "",
+			ctx:          context.TODO(),
+			expectedCode: http.StatusForbidden,
+			expectedErr:  ApiError{Message: "Listing decks requires the admin token"},
+		},
+		{
+			name:         "bad created_after",
+			query:        "?created_after=yesterday",
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "created_after must be a time in RFC 3339 format"},
+		},
+		{
+			name:         "bad remaining_below",
+			query:        "?remaining_below=0",
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "remaining_below must be greater than 0"},
+		},
+		{
+			name:         "limit too large",
+			query:        "?limit=101",
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "limit must be between 1 and 100"},
+		},
+		{
+			name:         "bad cursor",
+			query:        "?cursor=bad",
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "cursor must be a next_cursor returned by a previous request"},
+		},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/deck"+test.query, nil)
+		_, responseCode, err := HandleListDecks(req, nil, &mdc, test.ctx)
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+	}
+}
+
+func TestHandleCreateDeckPreset(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = d
+		return nil
+	}
+
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Preset: "piquet", ExcludeRanks: []string{"10", "j"}, ExcludeSuits: []string{"h"}})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	response, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if response.Remaining != 18 || responseCode != http.StatusCreated || err != nil {
+		t.Errorf("Failed for preset case: expected 18 cards, code %d and error %v, got %d, %d and %v", http.StatusCreated, nil, response.Remaining, responseCode, err)
+	}
+	if inserted.Preset != deck.PresetPiquet || !cmp.Equal(inserted.ExcludeRanks, []string{"0", "J"}) || !cmp.Equal(inserted.ExcludeSuits, []string{"H"}) {
+		t.Errorf("Failed for preset case: expected preset and canonical exclusions to be stored, got %v, %v and %v", inserted.Preset, inserted.ExcludeRanks, inserted.ExcludeSuits)
+	}
+
+	tests := []struct {
+		name        string
+		reqBody     CreateDeckRequestBody
+		expectedErr error
+	}{
+		{
+			name:        "unknown preset",
+			reqBody:     CreateDeckRequestBody{Preset: "skat"},
+			expectedErr: ApiError{Message: "Deck preset skat is unknown"},
+		},
+		{
+			name:        "invalid rank",
+			reqBody:     CreateDeckRequestBody{ExcludeRanks: []string{"11"}},
+			expectedErr: ApiError{Message: "Rank code 11 is invalid"},
+		},
+		{
+			name:        "invalid suit",
+			reqBody:     CreateDeckRequestBody{ExcludeSuits: []string{"X"}},
+			expectedErr: ApiError{Message: "Suit code X is invalid"},
+		},
+		{
+			name:        "everything excluded",
+			reqBody:     CreateDeckRequestBody{Preset: "euchre", ExcludeSuits: []string{"S", "D", "C", "H"}},
+			expectedErr: ApiError{Message: "Every card of the deck has been excluded"},
+		},
+		{
+			name:        "preset with custom deck",
+			reqBody:     CreateDeckRequestBody{Preset: "euchre", CustomDeck: true, WantedCards: []string{"AS"}},
+			expectedErr: ApiError{Message: "A preset cannot be combined with a custom deck"},
+		},
+	}
+	for _, test := range tests {
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+		_, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if responseCode != http.StatusBadRequest {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, http.StatusBadRequest, responseCode)
+		}
+	}
+}
+
+func TestHandleCreateDeckFamily(t *testing.T) {
+	mockParams := httprouter.Params{}
+	mockCtx := context.TODO()
+	var inserted database.DeckModel
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockInsertDeckFn = func(ctx context.Context, d database.DeckModel) error {
+		inserted = d
+		return nil
+	}
+
+	mockBody, _ := json.Marshal(CreateDeckRequestBody{Family: "tarot", ExcludeSuits: []string{"t"}})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	response, responseCode, err := HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if response.Remaining != 56 || responseCode != http.StatusCreated || err != nil {
+		t.Errorf("Failed for family case: expected 56 cards, code %d and error %v, got %d, %d and %v", http.StatusCreated, nil, response.Remaining, responseCode, err)
+	}
+	if inserted.Family != deck.FamilyTarot || !cmp.Equal(inserted.ExcludeSuits, []string{"T"}) {
+		t.Errorf("Failed for family case: expected family and exclusions to be stored, got %v and %v", inserted.Family, inserted.ExcludeSuits)
+	}
+
+	mockBody, _ = json.Marshal(CreateDeckRequestBody{Family: "hanafuda"})
+	req = httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "Deck family hanafuda is unknown"}
+	_, responseCode, err = HandleCreateDeck(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for unknown family case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusBadRequest {
+		t.Errorf("Failed for unknown family case: expected response code to be %d, got %d", http.StatusBadRequest, responseCode)
+	}
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	db "github.com/AbhilashJN/cards/database"
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Pile names end up as document keys in the database, so they are limited to
+// characters that are safe there.
+var pileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+type AddToPileRequestBody struct {
+	Cards         []string `json:"cards"`
+	NumberOfCards int      `json:"numberOfCards"`
+}
+
+type AddToPileResponseBody struct {
+	DeckId        string        `json:"deck_id"`
+	Pile          string        `json:"pile"`
+	Remaining     int           `json:"remaining"`
+	PileRemaining int           `json:"pile_remaining"`
+	Cards         deck.DeckJSON `json:"cards"`
+	Version       int64         `json:"-"`
+}
+
+type GetPileResponseBody struct {
+	DeckId    string `json:"deck_id"`
+	Pile      string `json:"pile"`
+	Remaining int    `json:"remaining"`
+	// Cards is only set when the caller asked for the cards to be revealed.
+	Cards   deck.DeckJSON `json:"cards,omitempty"`
+	Version int64         `json:"-"`
+}
+
+func HandleAddToPile(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (AddToPileResponseBody, int, error) {
+	var (
+		reqBody      AddToPileRequestBody
+		responseBody AddToPileResponseBody
+	)
+	reqUUID := ps.ByName("uuid")
+	pileName := ps.ByName("name")
+	if !pileNamePattern.MatchString(pileName) {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Pile name must be 1 to 64 letters, digits, '-' or '_'"}
+	}
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	if (len(reqBody.Cards) == 0) == (reqBody.NumberOfCards <= 0) {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Either a list of cards or a number of cards greater than 0 must be specified"}
+	}
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
+
+	movedCards, updatedDeck, err := db.AddToPileByUUID(ctx, dc, reqUUID, pileName, reqBody.Cards, reqBody.NumberOfCards, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.DeckId = updatedDeck.UUID
+	responseBody.Pile = pileName
+	responseBody.Remaining = len(updatedDeck.Cards)
+	responseBody.PileRemaining = len(updatedDeck.Piles[pileName])
+	responseBody.Cards = movedCards.ToDeckJSON()
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+func HandleGetPile(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (GetPileResponseBody, int, error) {
+	var responseBody GetPileResponseBody
+	reqUUID := ps.ByName("uuid")
+	pileName := ps.ByName("name")
+	reveal, code, err := revealRequested(r, ctx)
+	if err != nil {
+		return responseBody, code, err
+	}
+	ranking, err := rankingRequested(r.URL.Query())
+	if err != nil {
+		return responseBody, http.StatusBadRequest, err
+	}
+
+	resultDeck, err := dc.FindDeckByUUID(ctx, reqUUID)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+	pile, ok := resultDeck.Piles[pileName]
+	if !ok {
+		return responseBody, http.StatusNotFound, ApiError{Message: "Pile with this name does not exist"}
+	}
+
+	responseBody.DeckId = resultDeck.UUID
+	responseBody.Pile = pileName
+	responseBody.Remaining = len(pile)
+	if reveal {
+		if ranking != nil {
+			pile = append(deck.Deck{}, pile...)
+			pile.Sort(*ranking)
+		}
+		responseBody.Cards = pile.ToDeckJSON()
+	}
+	responseBody.Version = resultDeck.Version
+	return responseBody, http.StatusOK, nil
+}
+
+// rankingRequested returns the ranking to sort cards by, if the query has a
+// sort parameter, refined by the trump and suits parameters.
+func rankingRequested(query url.Values) (*deck.Ranking, error) {
+	name, trumpCode, suitCodes := query.Get("sort"), query.Get("trump"), query.Get("suits")
+	if name == "" {
+		if trumpCode != "" || suitCodes != "" {
+			return nil, ApiError{Message: "trump and suits can only be used with sort"}
+		}
+		return nil, nil
+	}
+	var trump *deck.CardSuit
+	if trumpCode != "" {
+		suit, err := deck.DecodeSuit(trumpCode)
+		if err != nil {
+			return nil, ApiError{Message: err.Error()}
+		}
+		trump = &suit
+	}
+	var suitOrder []deck.CardSuit
+	for _, code := range suitCodes {
+		suit, err := deck.DecodeSuit(string(code))
+		if err != nil {
+			return nil, ApiError{Message: err.Error()}
+		}
+		suitOrder = append(suitOrder, suit)
+	}
+	ranking, err := deck.NewRanking(deck.RankingName(name), trump, suitOrder)
+	if err != nil {
+		return nil, ApiError{Message: err.Error()}
+	}
+	return &ranking, nil
+}
+
+func HandleDrawFromPile(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (DrawCardsResponseBody, int, error) {
+	var (
+		reqBody      DrawCardsRequestBody
+		responseBody DrawCardsResponseBody
+	)
+	reqUUID := ps.ByName("uuid")
+	pileName := ps.ByName("name")
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	drawOpts, err := reqBody.drawOpts()
+	if err != nil {
+		return responseBody, http.StatusBadRequest, err
+	}
+	ifVersion, err := parseIfMatch(r)
+	if err != nil {
+		return responseBody, http.StatusPreconditionFailed, ApiError{Message: "Deck has been modified since it was last read"}
+	}
+
+	drawnCards, updatedDeck, err := db.DrawFromPileByUUID(ctx, dc, reqUUID, pileName, drawOpts, ifVersion)
+	if code, apiErr := deckErrorResponse(err); apiErr != nil {
+		return responseBody, code, apiErr
+	}
+
+	responseBody.Cards = drawnCards.ToDeckJSON()
+	responseBody.Version = updatedDeck.Version
+	return responseBody, http.StatusOK, nil
+}
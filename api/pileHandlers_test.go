@@ -0,0 +1,304 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AbhilashJN/cards/database"
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"github.com/julienschmidt/httprouter"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type HandleAddToPileTest struct {
+	pileName              string
+	reqBody               AddToPileRequestBody
+	expectedCode          int
+	expectedErr           error
+	expectedCards         deck.DeckJSON
+	expectedRemaining     int
+	expectedPileRemaining int
+}
+
+func getMockPileDeck() database.DeckModel {
+	return database.DeckModel{
+		UUID: "test-uuid-123",
+		Cards: deck.Deck{
+			{Value: deck.Ace, Suit: deck.Spades},
+			{Value: deck.Three, Suit: deck.Clubs},
+			{Value: deck.Nine, Suit: deck.Hearts},
+		},
+		Piles: map[string]deck.Deck{
+			"discard": {{Value: deck.King, Suit: deck.Diamonds}},
+		},
+		Version: 2,
+	}
+}
+
+func TestHandleAddToPile(t *testing.T) {
+	mockCtx := context.TODO()
+	tests := []HandleAddToPileTest{
+		{
+			pileName:              "player1",
+			reqBody:               AddToPileRequestBody{NumberOfCards: 2},
+			expectedCode:          http.StatusOK,
+			expectedCards:         deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(),
+			expectedRemaining:     1,
+			expectedPileRemaining: 2,
+		},
+		{
+			pileName:              "discard",
+			reqBody:               AddToPileRequestBody{Cards: []string{"9H"}},
+			expectedCode:          http.StatusOK,
+			expectedCards:         deck.Deck{{Value: deck.Nine, Suit: deck.Hearts}}.ToDeckJSON(),
+			expectedRemaining:     2,
+			expectedPileRemaining: 2,
+		},
+		{
+			pileName:     "discard",
+			reqBody:      AddToPileRequestBody{Cards: []string{"KD"}},
+			expectedCode: http.StatusNotFound,
+			expectedErr:  ApiError{Message: "Card KD is not in the deck"},
+		},
+		{
+			pileName:     "discard",
+			reqBody:      AddToPileRequestBody{NumberOfCards: 4},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Requested number of cards is greater than the cards remaining in the deck"},
+		},
+		{
+			pileName:     "discard",
+			reqBody:      AddToPileRequestBody{},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Either a list of cards or a number of cards greater than 0 must be specified"},
+		},
+		{
+			pileName:     "discard",
+			reqBody:      AddToPileRequestBody{Cards: []string{"AS"}, NumberOfCards: 1},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Either a list of cards or a number of cards greater than 0 must be specified"},
+		},
+		{
+			pileName:     "not.valid",
+			reqBody:      AddToPileRequestBody{NumberOfCards: 1},
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Pile name must be 1 to 64 letters, digits, '-' or '_'"},
+		},
+	}
+
+	for _, test := range tests {
+		mdc := mockDeckCRUDOperator{}
+		mdc.mockUpdateDeckByUUID = mockUpdateOf(getMockPileDeck())
+		mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: test.pileName}}
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("POST", "/deck/test-uuid-123/pile/"+test.pileName, bytes.NewReader(mockBody))
+		response, responseCode, err := HandleAddToPile(req, mockParams, &mdc, mockCtx)
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %v: expected response code to be %d, got %d", test.reqBody, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %v: expected error to be %v, got %v", test.reqBody, test.expectedErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if !cmp.Equal(response.Cards, test.expectedCards) {
+			t.Errorf("Failed for %v: expected cards to be %v, got %v", test.reqBody, test.expectedCards, response.Cards)
+		}
+		if response.Remaining != test.expectedRemaining {
+			t.Errorf("Failed for %v: expected remaining to be %d, got %d", test.reqBody, test.expectedRemaining, response.Remaining)
+		}
+		if response.PileRemaining != test.expectedPileRemaining {
+			t.Errorf("Failed for %v: expected pile remaining to be %d, got %d", test.reqBody, test.expectedPileRemaining, response.PileRemaining)
+		}
+	}
+}
+
+func TestHandleGetPile(t *testing.T) {
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return getMockPileDeck(), nil
+	}
+
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: "discard"}}
+	req := httptest.NewRequest("GET", "/deck/test-uuid-123/pile/discard", bytes.NewReader([]byte{}))
+	expectedResponse := GetPileResponseBody{
+		DeckId:    "test-uuid-123",
+		Pile:      "discard",
+		Remaining: 1,
+		Version:   2,
+	}
+	response, responseCode, err := HandleGetPile(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-123/pile/discard?reveal=true", bytes.NewReader([]byte{}))
+	expectedResponse.Cards = deck.Deck{{Value: deck.King, Suit: deck.Diamonds}}.ToDeckJSON()
+	response, _, err = HandleGetPile(req, mockParams, &mdc, WithPrivilege(mockCtx, true))
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for reveal case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if err != nil {
+		t.Errorf("Failed for reveal case: expected error to be %v, got %v", nil, err)
+	}
+	_, responseCode, _ = HandleGetPile(req, mockParams, &mdc, mockCtx)
+	if responseCode != http.StatusForbidden {
+		t.Errorf("Failed for unprivileged reveal case: expected response code to be %d, got %d", http.StatusForbidden, responseCode)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-123/pile/discard", bytes.NewReader([]byte{}))
+
+	mockParams = httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: "player2"}}
+	expectedErr := ApiError{Message: "Pile with this name does not exist"}
+	_, responseCode, err = HandleGetPile(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for pile not found case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusNotFound {
+		t.Errorf("Failed for pile not found case: expected response code to be %d, got %d", http.StatusNotFound, responseCode)
+	}
+
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return database.DeckModel{}, mongo.ErrNoDocuments
+	}
+	expectedErr = ApiError{Message: "Deck with this id does not exist"}
+	_, responseCode, err = HandleGetPile(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for deck not found case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusNotFound {
+		t.Errorf("Failed for deck not found case: expected response code to be %d, got %d", http.StatusNotFound, responseCode)
+	}
+}
+
+func TestHandleDrawFromPile(t *testing.T) {
+	mockCtx := context.TODO()
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockUpdateDeckByUUID = mockUpdateOf(getMockPileDeck())
+
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: "discard"}}
+	mockBody, _ := json.Marshal(DrawCardsRequestBody{NumberOfCards: 1})
+	req := httptest.NewRequest("PATCH", "/deck/test-uuid-123/pile/discard", bytes.NewReader(mockBody))
+	expectedResponse := DrawCardsResponseBody{
+		Cards:   deck.Deck{{Value: deck.King, Suit: deck.Diamonds}}.ToDeckJSON(),
+		Version: 3,
+	}
+	response, responseCode, err := HandleDrawFromPile(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(response, expectedResponse) {
+		t.Errorf("Failed for success case: expected response to be %v, got %v", expectedResponse, response)
+	}
+	if responseCode != http.StatusOK {
+		t.Errorf("Failed for success case: expected response code to be %d, got %d", http.StatusOK, responseCode)
+	}
+	if err != nil {
+		t.Errorf("Failed for success case: expected error to be %v, got %v", nil, err)
+	}
+
+	mockParams = httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: "player2"}}
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-123/pile/player2", bytes.NewReader(mockBody))
+	expectedErr := ApiError{Message: "Pile with this name does not exist"}
+	_, responseCode, err = HandleDrawFromPile(req, mockParams, &mdc, mockCtx)
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for pile not found case: expected error to be %v, got %v", expectedErr, err)
+	}
+	if responseCode != http.StatusNotFound {
+		t.Errorf("Failed for pile not found case: expected response code to be %d, got %d", http.StatusNotFound, responseCode)
+	}
+}
+
+func TestHandleGetPileSorted(t *testing.T) {
+	mockCtx := WithPrivilege(context.TODO(), true)
+	mockDeck := getMockPileDeck()
+	mockDeck.Piles["player1"] = deck.Deck{
+		{Value: deck.Two, Suit: deck.Clubs}, {Value: deck.Ace, Suit: deck.Diamonds},
+		{Value: deck.King, Suit: deck.Spades}, {Value: deck.Ten, Suit: deck.Hearts},
+	}
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		return mockDeck, nil
+	}
+	mockParams := httprouter.Params{{Key: "uuid", Value: "test-uuid-123"}, {Key: "name", Value: "player1"}}
+
+	tests := []struct {
+		name          string
+		query         string
+		expectedCards deck.Deck
+		expectedCode  int
+		expectedErr   error
+	}{
+		{
+			name:  "unsorted",
+			query: "?reveal=true",
+			expectedCards: deck.Deck{
+				{Value: deck.Two, Suit: deck.Clubs}, {Value: deck.Ace, Suit: deck.Diamonds},
+				{Value: deck.King, Suit: deck.Spades}, {Value: deck.Ten, Suit: deck.Hearts},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "bridge order",
+			query: "?reveal=true&sort=ace-high&suits=SHDC",
+			expectedCards: deck.Deck{
+				{Value: deck.King, Suit: deck.Spades}, {Value: deck.Ten, Suit: deck.Hearts},
+				{Value: deck.Ace, Suit: deck.Diamonds}, {Value: deck.Two, Suit: deck.Clubs},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:  "clubs trump",
+			query: "?reveal=true&sort=ace-low&trump=c&suits=SHDC",
+			expectedCards: deck.Deck{
+				{Value: deck.Two, Suit: deck.Clubs}, {Value: deck.King, Suit: deck.Spades},
+				{Value: deck.Ten, Suit: deck.Hearts}, {Value: deck.Ace, Suit: deck.Diamonds},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unknown ranking",
+			query:        "?reveal=true&sort=queen-high",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Ranking queen-high is unknown"},
+		},
+		{
+			name:         "invalid suit",
+			query:        "?reveal=true&sort=ace-high&suits=SHDX",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Suit code X is invalid"},
+		},
+		{
+			name:         "trump without sort",
+			query:        "?reveal=true&trump=H",
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "trump and suits can only be used with sort"},
+		},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/deck/test-uuid-123/pile/player1"+test.query, nil)
+		response, responseCode, err := HandleGetPile(req, mockParams, &mdc, mockCtx)
+		if test.expectedErr == nil && !cmp.Equal(response.Cards, test.expectedCards.ToDeckJSON()) {
+			t.Errorf("Failed for %s case: expected cards to be %v, got %v", test.name, test.expectedCards.ToDeckJSON(), response.Cards)
+		}
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+	if stored := mockDeck.Piles["player1"][0]; stored != (deck.Card{Value: deck.Two, Suit: deck.Clubs}) {
+		t.Errorf("Failed for sorted pile: expected the stored pile to be unchanged, got %v first", stored)
+	}
+}
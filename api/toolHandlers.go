@@ -0,0 +1,178 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	db "github.com/AbhilashJN/cards/database"
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/AbhilashJN/cards/poker"
+	"github.com/julienschmidt/httprouter"
+)
+
+type EvaluateHandRequestBody struct {
+	Cards  []string `json:"cards"`
+	DeckId string   `json:"deckId"`
+	Piles  []string `json:"piles"`
+}
+
+type EvaluateHandResponseBody struct {
+	Category string        `json:"category"`
+	Score    poker.Score   `json:"score"`
+	Cards    deck.DeckJSON `json:"cards"`
+	Kickers  deck.DeckJSON `json:"kickers"`
+}
+
+const (
+	defaultEquityIterations = 100000
+	maxEquityIterations     = 1000000
+)
+
+type EquityRequestBody struct {
+	Hands      [][]string `json:"hands"`
+	Board      []string   `json:"board"`
+	Iterations *int       `json:"iterations"`
+	Seed       *int64     `json:"seed"`
+}
+
+type PlayerEquityJSON struct {
+	Cards  deck.DeckJSON `json:"cards"`
+	Win    float64       `json:"win"`
+	Tie    float64       `json:"tie"`
+	Equity float64       `json:"equity"`
+}
+
+type EquityResponseBody struct {
+	Players []PlayerEquityJSON `json:"players"`
+	Boards  int                `json:"boards"`
+	Exact   bool               `json:"exact"`
+}
+
+// HandleEvaluateHand finds the best poker hand in the given cards, or in the
+// cards of the given piles of a deck, such as a player's hole cards and the
+// board. Evaluating piles shows their cards, so it is a privileged operation.
+func HandleEvaluateHand(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (EvaluateHandResponseBody, int, error) {
+	var (
+		reqBody      EvaluateHandRequestBody
+		responseBody EvaluateHandResponseBody
+	)
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	if (len(reqBody.Cards) == 0) == (reqBody.DeckId == "" || len(reqBody.Piles) == 0) {
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Either a list of cards or a deck id and piles must be specified"}
+	}
+
+	var cards deck.Deck
+	if len(reqBody.Cards) > 0 {
+		cards, err = decodeCards(reqBody.Cards)
+		if err != nil {
+			return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+		}
+	} else {
+		if !isPrivileged(ctx) {
+			return responseBody, http.StatusForbidden, ApiError{Message: "Evaluating piles requires the admin token"}
+		}
+		resultDeck, err := dc.FindDeckByUUID(ctx, reqBody.DeckId)
+		if code, apiErr := deckErrorResponse(err); apiErr != nil {
+			return responseBody, code, apiErr
+		}
+		for _, name := range reqBody.Piles {
+			pile, ok := resultDeck.Piles[name]
+			if !ok {
+				return responseBody, http.StatusNotFound, ApiError{Message: "Pile with this name does not exist"}
+			}
+			cards = append(cards, pile...)
+		}
+	}
+
+	hand, err := poker.Evaluate(cards)
+	if err != nil {
+		return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+	}
+	responseBody.Category = hand.Category.String()
+	responseBody.Score = hand.Score
+	responseBody.Cards = hand.Cards.ToDeckJSON()
+	responseBody.Kickers = hand.Kickers.ToDeckJSON()
+	return responseBody, http.StatusOK, nil
+}
+
+func decodeCards(cardCodes []string) (deck.Deck, error) {
+	cards := make(deck.Deck, len(cardCodes))
+	for i, cardCode := range cardCodes {
+		value, suit, err := deck.DecodeValueAndSuit(cardCode)
+		if err != nil {
+			return nil, err
+		}
+		cards[i] = deck.Card{Value: value, Suit: suit}
+	}
+	return cards, nil
+}
+
+// HandleEquity calculates how often each player's Texas Hold'em hole cards
+// win and tie once the board is complete. It stops sampling boards when ctx
+// is done, so the caller's deadline bounds the time it takes.
+func HandleEquity(r *http.Request, ps httprouter.Params, dc db.DeckCRUDer, ctx context.Context) (EquityResponseBody, int, error) {
+	var (
+		reqBody      EquityRequestBody
+		responseBody EquityResponseBody
+	)
+	err := json.NewDecoder(r.Body).Decode(&reqBody)
+	if err != nil {
+		log.Println("Error parsing request body", err)
+		return responseBody, http.StatusBadRequest, ApiError{Message: "Request body is malformed"}
+	}
+	iterations := defaultEquityIterations
+	if reqBody.Iterations != nil {
+		iterations = *reqBody.Iterations
+	}
+	if iterations < 1 || iterations > maxEquityIterations {
+		return responseBody, http.StatusBadRequest, ApiError{Message: fmt.Sprintf("iterations must be between 1 and %d", maxEquityIterations)}
+	}
+
+	opts := poker.EquityOpts{Iterations: iterations}
+	for _, codes := range reqBody.Hands {
+		hand, err := decodeCards(codes)
+		if err != nil {
+			return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+		}
+		opts.Hands = append(opts.Hands, hand)
+	}
+	if opts.Board, err = decodeCards(reqBody.Board); err != nil {
+		return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+	}
+	if opts.Source, err = deck.NewSource(deck.RNGMath, reqBody.Seed); err != nil {
+		return responseBody, http.StatusInternalServerError, ApiError{Message: "Internal Server Error"}
+	}
+
+	equity, err := poker.CalculateEquity(ctx, opts)
+	if err == context.DeadlineExceeded || err == context.Canceled {
+		return responseBody, http.StatusServiceUnavailable, ApiError{Message: "Equity could not be calculated in time"}
+	}
+	if err != nil {
+		return responseBody, http.StatusBadRequest, ApiError{Message: err.Error()}
+	}
+	responseBody.Players = make([]PlayerEquityJSON, len(equity.Players))
+	for i, player := range equity.Players {
+		responseBody.Players[i] = PlayerEquityJSON{
+			Cards:  opts.Hands[i].ToDeckJSON(),
+			Win:    percentage(player.Win),
+			Tie:    percentage(player.Tie),
+			Equity: percentage(player.Equity),
+		}
+	}
+	responseBody.Boards = equity.Boards
+	responseBody.Exact = equity.Exact
+	return responseBody, http.StatusOK, nil
+}
+
+// percentage turns a fraction into a percentage with two decimals.
+func percentage(fraction float64) float64 {
+	return math.Round(fraction*10000) / 100
+}
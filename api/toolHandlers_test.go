@@ -0,0 +1,270 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AbhilashJN/cards/database"
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestHandleEvaluateHand(t *testing.T) {
+	mockCtx := WithPrivilege(context.TODO(), true)
+	mdc := mockDeckCRUDOperator{}
+	mdc.mockFindDeckByUUID = func(ctx context.Context, uuid string) (database.DeckModel, error) {
+		if uuid != "test-uuid-123" {
+			return database.DeckModel{}, mongo.ErrNoDocuments
+		}
+		return database.DeckModel{
+			UUID: "test-uuid-123",
+			Piles: map[string]deck.Deck{
+				"player1": {{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Hearts}},
+				"board": {
+					{Value: deck.Ace, Suit: deck.Diamonds}, {Value: deck.Seven, Suit: deck.Clubs},
+					{Value: deck.Seven, Suit: deck.Spades}, {Value: deck.Two, Suit: deck.Hearts},
+					{Value: deck.Nine, Suit: deck.Diamonds},
+				},
+			},
+		}, nil
+	}
+
+	tests := []struct {
+		name             string
+		reqBody          EvaluateHandRequestBody
+		ctx              context.Context
+		expectedResponse EvaluateHandResponseBody
+		expectedCode     int
+		expectedErr      error
+	}{
+		{
+			name:    "cards",
+			reqBody: EvaluateHandRequestBody{Cards: []string{"qh", "10S", "QS", "2D", "0C", "5S", "QC"}},
+			ctx:     context.TODO(),
+			expectedResponse: EvaluateHandResponseBody{
+				Category: "full house",
+				Score:    0x6a8000,
+				Cards:    deck.Deck{{Value: deck.Queen, Suit: deck.Hearts}, {Value: deck.Queen, Suit: deck.Spades}, {Value: deck.Queen, Suit: deck.Clubs}, {Value: deck.Ten, Suit: deck.Spades}, {Value: deck.Ten, Suit: deck.Clubs}}.ToDeckJSON(),
+				Kickers:  deck.DeckJSON{},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "piles",
+			reqBody: EvaluateHandRequestBody{DeckId: "test-uuid-123", Piles: []string{"player1", "board"}},
+			ctx:     mockCtx,
+			expectedResponse: EvaluateHandResponseBody{
+				Category: "full house",
+				Score:    0x6c5000,
+				Cards:    deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Hearts}, {Value: deck.Ace, Suit: deck.Diamonds}, {Value: deck.Seven, Suit: deck.Clubs}, {Value: deck.Seven, Suit: deck.Spades}}.ToDeckJSON(),
+				Kickers:  deck.DeckJSON{},
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "nothing to evaluate",
+			reqBody:      EvaluateHandRequestBody{DeckId: "test-uuid-123"},
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Either a list of cards or a deck id and piles must be specified"},
+		},
+		{
+			name:         "cards and piles",
+			reqBody:      EvaluateHandRequestBody{Cards: []string{"AS"}, DeckId: "test-uuid-123", Piles: []string{"board"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Either a list of cards or a deck id and piles must be specified"},
+		},
+		{
+			name:         "invalid card code",
+			reqBody:      EvaluateHandRequestBody{Cards: []string{"AS", "KS", "QS", "JS", "ZX"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Card code ZX is invalid"},
+		},
+		{
+			name:         "too few cards",
+			reqBody:      EvaluateHandRequestBody{DeckId: "test-uuid-123", Piles: []string{"player1"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "A poker hand must have between 5 and 7 cards, got 2"},
+		},
+		{
+			name:         "duplicate card",
+			reqBody:      EvaluateHandRequestBody{Cards: []string{"AS", "KS", "QS", "JS", "as"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Card AS is dealt more than once"},
+		},
+		{
+			name:         "unprivileged piles",
+			reqBody:      EvaluateHandRequestBody{DeckId: "test-uuid-123", Piles: []string{"player1", "board"}},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusForbidden,
+			expectedErr:  ApiError{Message: "Evaluating piles requires the admin token"},
+		},
+		{
+			name:         "missing pile",
+			reqBody:      EvaluateHandRequestBody{DeckId: "test-uuid-123", Piles: []string{"player2", "board"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusNotFound,
+			expectedErr:  ApiError{Message: "Pile with this name does not exist"},
+		},
+		{
+			name:         "missing deck",
+			reqBody:      EvaluateHandRequestBody{DeckId: "test-uuid-456", Piles: []string{"board"}},
+			ctx:          mockCtx,
+			expectedCode: http.StatusNotFound,
+			expectedErr:  ApiError{Message: "Deck with this id does not exist"},
+		},
+	}
+	for _, test := range tests {
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("POST", "/tools/evaluate", bytes.NewReader(mockBody))
+		response, responseCode, err := HandleEvaluateHand(req, nil, &mdc, test.ctx)
+		if !cmp.Equal(response, test.expectedResponse) {
+			t.Errorf("Failed for %s case: expected response to be %v, got %v", test.name, test.expectedResponse, response)
+		}
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func TestHandleEquity(t *testing.T) {
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	iterations := func(n int) *int { return &n }
+	seed := int64(7)
+
+	tests := []struct {
+		name             string
+		reqBody          EquityRequestBody
+		ctx              context.Context
+		expectedResponse EquityResponseBody
+		expectedCode     int
+		expectedErr      error
+	}{
+		{
+			name:    "turn",
+			reqBody: EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Board: []string{"2C", "7D", "9S", "JD"}},
+			ctx:     context.TODO(),
+			expectedResponse: EquityResponseBody{
+				Players: []PlayerEquityJSON{
+					{Cards: deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Hearts}}.ToDeckJSON(), Win: 95.45, Equity: 95.45},
+					{Cards: deck.Deck{{Value: deck.King, Suit: deck.Spades}, {Value: deck.King, Suit: deck.Hearts}}.ToDeckJSON(), Win: 4.55, Equity: 4.55},
+				},
+				Boards: 44,
+				Exact:  true,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:    "split pot",
+			reqBody: EquityRequestBody{Hands: [][]string{{"2C", "3C"}, {"2D", "3D"}}, Board: []string{"AS", "KS", "QS", "JS", "0S"}},
+			ctx:     context.TODO(),
+			expectedResponse: EquityResponseBody{
+				Players: []PlayerEquityJSON{
+					{Cards: deck.Deck{{Value: deck.Two, Suit: deck.Clubs}, {Value: deck.Three, Suit: deck.Clubs}}.ToDeckJSON(), Tie: 100, Equity: 50},
+					{Cards: deck.Deck{{Value: deck.Two, Suit: deck.Diamonds}, {Value: deck.Three, Suit: deck.Diamonds}}.ToDeckJSON(), Tie: 100, Equity: 50},
+				},
+				Boards: 1,
+				Exact:  true,
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "too many iterations",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Iterations: iterations(maxEquityIterations + 1)},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "iterations must be between 1 and 1000000"},
+		},
+		{
+			name:         "no iterations",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Iterations: iterations(0)},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "iterations must be between 1 and 1000000"},
+		},
+		{
+			name:         "invalid card code",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "ZZ"}}},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Card code ZZ is invalid"},
+		},
+		{
+			name:         "one player",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}}},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Equity needs between 2 and 10 players, got 1"},
+		},
+		{
+			name:         "one hole card",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS"}}},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Player 2 must have 2 hole cards, got 1"},
+		},
+		{
+			name:         "card on board and in hand",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Board: []string{"2C", "KH", "9S"}},
+			ctx:          context.TODO(),
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  ApiError{Message: "Card KH is dealt more than once"},
+		},
+		{
+			name:         "out of time",
+			reqBody:      EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Seed: &seed},
+			ctx:          cancelledCtx,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedErr:  ApiError{Message: "Equity could not be calculated in time"},
+		},
+	}
+	for _, test := range tests {
+		mockBody, _ := json.Marshal(test.reqBody)
+		req := httptest.NewRequest("POST", "/tools/equity", bytes.NewReader(mockBody))
+		response, responseCode, err := HandleEquity(req, nil, &mockDeckCRUDOperator{}, test.ctx)
+		if !cmp.Equal(response, test.expectedResponse) {
+			t.Errorf("Failed for %s case: expected response to be %v, got %v", test.name, test.expectedResponse, response)
+		}
+		if responseCode != test.expectedCode {
+			t.Errorf("Failed for %s case: expected response code to be %d, got %d", test.name, test.expectedCode, responseCode)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func TestHandleEquitySeeded(t *testing.T) {
+	seed := int64(7)
+	iterations := 2000
+	reqBody := EquityRequestBody{Hands: [][]string{{"AS", "AH"}, {"KS", "KH"}}, Iterations: &iterations, Seed: &seed}
+	var responses []EquityResponseBody
+	for i := 0; i < 2; i++ {
+		mockBody, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/tools/equity", bytes.NewReader(mockBody))
+		response, _, err := HandleEquity(req, nil, &mockDeckCRUDOperator{}, context.TODO())
+		if err != nil {
+			t.Fatalf("Expected error to be %v, got %v", nil, err)
+		}
+		if response.Boards != iterations || response.Exact {
+			t.Errorf("Expected %d sampled boards, got %d with exact %v", iterations, response.Boards, response.Exact)
+		}
+		responses = append(responses, response)
+	}
+	if !cmp.Equal(responses[0], responses[1]) {
+		t.Errorf("Expected the same seed to give the same response, got %v and %v", responses[0], responses[1])
+	}
+}
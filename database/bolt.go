@@ -0,0 +1,161 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var decksBucket = []byte("decks")
+
+// BoltDeckStore keeps decks in a single local file. Decks are stored as BSON
+// documents keyed by their uuid, the same shape they have in MongoDB.
+type BoltDeckStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltDeckStore(path string) (*BoltDeckStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(decksBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltDeckStore{db: db}, nil
+}
+
+func (b *BoltDeckStore) Close() error {
+	return b.db.Close()
+}
+
+func (b *BoltDeckStore) InsertDeck(ctx context.Context, deckItem DeckModel) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		if bucket.Get([]byte(deckItem.UUID)) != nil {
+			return ErrDuplicateUUID
+		}
+		return putDeck(bucket, deckItem)
+	})
+}
+
+func (b *BoltDeckStore) FindDeckByUUID(ctx context.Context, uuid string) (DeckModel, error) {
+	var resultDeck DeckModel
+	err := b.db.View(func(tx *bolt.Tx) error {
+		var err error
+		resultDeck, err = getDeck(tx.Bucket(decksBucket), uuid)
+		return err
+	})
+	return resultDeck, err
+}
+
+// UpdateDeckByUUID runs mutate inside a single read-write transaction. Bolt
+// allows only one such transaction at a time, so the update is atomic.
+func (b *BoltDeckStore) UpdateDeckByUUID(ctx context.Context, uuid string, mutate DeckMutation) (DeckModel, error) {
+	var deckItem DeckModel
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		var err error
+		deckItem, err = getDeck(bucket, uuid)
+		if err != nil {
+			return err
+		}
+		readVersion := deckItem.Version
+		if err = mutate(&deckItem); err != nil {
+			return err
+		}
+		deckItem.Version = readVersion + 1
+		deckItem.LastActivity = activityTime()
+		return putDeck(bucket, deckItem)
+	})
+	if err != nil {
+		return DeckModel{}, err
+	}
+	return deckItem, nil
+}
+
+func (b *BoltDeckStore) DeleteDeckByUUID(ctx context.Context, uuid string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		if bucket.Get([]byte(uuid)) == nil {
+			return mongo.ErrNoDocuments
+		}
+		return bucket.Delete([]byte(uuid))
+	})
+}
+
+// PurgeDecksIdleSince deletes every deck whose last activity was before
+// cutoff and returns how many were deleted.
+func (b *BoltDeckStore) PurgeDecksIdleSince(ctx context.Context, cutoff time.Time) (int, error) {
+	purged := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(decksBucket)
+		var idle [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var deckItem DeckModel
+			if err := bson.Unmarshal(v, &deckItem); err != nil {
+				return err
+			}
+			if isIdleSince(deckItem, cutoff) {
+				idle = append(idle, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range idle {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		purged = len(idle)
+		return nil
+	})
+	return purged, err
+}
+
+// ListDecks returns one page of the decks matching opts.Filter.
+func (b *BoltDeckStore) ListDecks(ctx context.Context, opts ListDecksOpts) (DeckPage, error) {
+	var decks []DeckModel
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(decksBucket).ForEach(func(k, v []byte) error {
+			var deckItem DeckModel
+			if err := bson.Unmarshal(v, &deckItem); err != nil {
+				return err
+			}
+			decks = append(decks, deckItem)
+			return nil
+		})
+	})
+	if err != nil {
+		return DeckPage{}, err
+	}
+	return pageOf(decks, opts)
+}
+
+func getDeck(bucket *bolt.Bucket, uuid string) (DeckModel, error) {
+	var deckItem DeckModel
+	data := bucket.Get([]byte(uuid))
+	if data == nil {
+		return deckItem, mongo.ErrNoDocuments
+	}
+	err := bson.Unmarshal(data, &deckItem)
+	return deckItem, err
+}
+
+func putDeck(bucket *bolt.Bucket, deckItem DeckModel) error {
+	data, err := bson.Marshal(deckItem)
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(deckItem.UUID), data)
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBoltDeckStorePersistsAcrossReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cards.db")
+	ctx := context.TODO()
+	store, err := OpenBoltDeckStore(path)
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	cards, _ := deck.New(&deck.NewDeckOpts{})
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Version: 1})
+	drawn, updated, err := DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 5}, 1)
+	if err != nil {
+		t.Errorf("Failed for draw: expected error to be %v, got %v", nil, err)
+	}
+	if !cmp.Equal(drawn, cards[:5]) {
+		t.Errorf("Failed for draw: expected drawn cards to be %v, got %v", cards[:5], drawn)
+	}
+	store.Close()
+
+	store, err = OpenBoltDeckStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reopen bolt store: %v", err)
+	}
+	defer store.Close()
+	found, err := store.FindDeckByUUID(ctx, "test-uuid-123")
+	if err != nil {
+		t.Errorf("Failed for find after reopen: expected error to be %v, got %v", nil, err)
+	}
+	expected := DeckModel{UUID: "test-uuid-123", Cards: cards[5:], Drawn: cards[:5], Version: 2, LastActivity: updated.LastActivity}
+	if !cmp.Equal(found, expected) {
+		t.Errorf("Failed for find after reopen: expected %v, got %v", expected, found)
+	}
+}
+
+func TestBoltDeckStoreErrors(t *testing.T) {
+	store, err := OpenBoltDeckStore(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.TODO()
+
+	if _, err := store.FindDeckByUUID(ctx, "missing"); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for find missing deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123"})
+	if err := store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123"}); err != ErrDuplicateUUID {
+		t.Errorf("Failed for duplicate insert: expected error to be %v, got %v", ErrDuplicateUUID, err)
+	}
+	if _, _, err := DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != (deck.ErrDrawCardsSizeExceeded{}) {
+		t.Errorf("Failed for draw from empty deck: expected error to be %v, got %v", deck.ErrDrawCardsSizeExceeded{}, err)
+	}
+	if err := store.DeleteDeckByUUID(ctx, "test-uuid-123"); err != nil {
+		t.Errorf("Failed for delete: expected error to be %v, got %v", nil, err)
+	}
+	if err := store.DeleteDeckByUUID(ctx, "test-uuid-123"); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for delete missing deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+}
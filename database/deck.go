@@ -2,21 +2,110 @@ package database
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/AbhilashJN/cards/deck"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxUpdateRetries bounds how many times a guarded update is retried when
+// another writer changes the deck between the read and the write.
+const maxUpdateRetries = 10
+
+// AnyVersion is the ifVersion of changes that do not depend on the version of
+// the deck. Decks stored before versions were introduced are at version 0,
+// so 0 is a version like any other.
+const AnyVersion int64 = -1
+
+var (
+	ErrUpdateConflict  = errors.New("deck was modified concurrently too many times")
+	ErrVersionMismatch = errors.New("deck version does not match the expected version")
+	ErrDeckClosed      = errors.New("deck has been closed")
+	ErrNotFairDeck     = errors.New("deck was not created with the fair RNG")
+	// ErrFairDeckNotSeeded is returned when a fair deck is changed before it
+	// has been given its client seed and shuffled.
+	ErrFairDeckNotSeeded = errors.New("fair deck has no client seed yet")
+	ErrFairDeckSeeded    = errors.New("fair deck already has a client seed")
 )
 
 type DeckModel struct {
 	UUID     string    `bson:"uuid"`
 	Cards    deck.Deck `bson:"cards"`
 	Shuffled bool      `bson:"shuffled"`
+	Version  int64     `bson:"version"`
+	// Closed decks can still be read but no longer changed.
+	Closed bool                 `bson:"closed,omitempty"`
+	Piles  map[string]deck.Deck `bson:"piles,omitempty"`
+	// Original is every card the deck was created with. Each of them is
+	// always in exactly one of Cards, Drawn or a pile.
+	Original deck.Deck `bson:"original"`
+	Drawn    deck.Deck `bson:"drawn"`
+	// Seed is set if the deck was created with a seed for reproducible
+	// shuffles.
+	Seed *int64 `bson:"seed,omitempty"`
+	// RNG is the random number generator the deck is shuffled with.
+	RNG deck.RNG `bson:"rng,omitempty"`
+	// Fair is set for decks created with deck.RNGFair. They are shuffled
+	// once their client seed is known.
+	Fair *FairShuffleModel `bson:"fair,omitempty"`
+	// The remaining fields record how the deck was created.
+	ShuffleMethod deck.ShuffleMethod `bson:"shuffle_method,omitempty"`
+	Passes        int                `bson:"passes,omitempty"`
+	// WantedCards holds the canonical codes of the cards of a custom deck.
+	WantedCards []string    `bson:"wanted_cards,omitempty"`
+	Family      deck.Family `bson:"family,omitempty"`
+	Preset      deck.Preset `bson:"preset,omitempty"`
+	// ExcludeRanks and ExcludeSuits hold the canonical codes of the values
+	// and suits that were left out of the deck.
+	ExcludeRanks  []string  `bson:"exclude_ranks,omitempty"`
+	ExcludeSuits  []string  `bson:"exclude_suits,omitempty"`
+	Decks         int       `bson:"decks,omitempty"`
+	IncludeJokers bool      `bson:"include_jokers,omitempty"`
+	CreatedAt     time.Time `bson:"created_at"`
+	OriginalSize  int       `bson:"original_size"`
+	// Owner and Tags are free-form labels given by the creator of the deck.
+	Owner string   `bson:"owner,omitempty"`
+	Tags  []string `bson:"tags,omitempty"`
+	// LastActivity is when the deck was created or last changed. Decks
+	// without one never expire.
+	LastActivity time.Time `bson:"last_activity,omitempty"`
+}
+
+// FairShuffleModel holds what is needed to prove a provably fair shuffle.
+// ServerSeed must not be shown to players until the deck is exhausted.
+// ClientSeed is empty until the players send it, after the Commitment has
+// been published.
+type FairShuffleModel struct {
+	ServerSeed string    `bson:"server_seed"`
+	Commitment string    `bson:"commitment"`
+	ClientSeed string    `bson:"client_seed"`
+	Unshuffled deck.Deck `bson:"unshuffled"`
 }
 
+type ErrCardNotDrawn struct {
+	CardCode string
+}
+
+func (e ErrCardNotDrawn) Error() string {
+	return fmt.Sprintf("Card %s has not been drawn from the deck", e.CardCode)
+}
+
+// DeckMutation modifies a deck in place. If it returns an error the deck is
+// left unchanged and the error is passed back to the caller.
+type DeckMutation func(*DeckModel) error
+
 type DeckCRUDer interface {
 	InsertDeck(context.Context, DeckModel) error
 	FindDeckByUUID(context.Context, string) (DeckModel, error)
-	UpdateDeckByUUID(context.Context, string, bson.D) error
+	// UpdateDeckByUUID atomically applies mutate to the stored deck, bumps
+	// its Version, sets its LastActivity and returns the deck as written.
+	UpdateDeckByUUID(context.Context, string, DeckMutation) (DeckModel, error)
+	DeleteDeckByUUID(context.Context, string) error
+	ListDecks(context.Context, ListDecksOpts) (DeckPage, error)
 }
 
 type DeckCRUDOperator struct {
@@ -35,7 +124,262 @@ func (d *DeckCRUDOperator) FindDeckByUUID(ctx context.Context, uuid string) (Dec
 	return resultDeck, err
 }
 
-func (d *DeckCRUDOperator) UpdateDeckByUUID(ctx context.Context, uuid string, updateQuery bson.D) error {
-	_, err := d.Collection.UpdateOne(ctx, bson.D{{Key: "uuid", Value: uuid}}, updateQuery)
-	return err
+// UpdateDeckByUUID reads the deck, applies mutate and writes it back only if
+// the stored version is still the one that was read, retrying on conflict.
+func (d *DeckCRUDOperator) UpdateDeckByUUID(ctx context.Context, uuid string, mutate DeckMutation) (DeckModel, error) {
+	for i := 0; i < maxUpdateRetries; i++ {
+		deckItem, err := d.FindDeckByUUID(ctx, uuid)
+		if err != nil {
+			return DeckModel{}, err
+		}
+		readVersion := deckItem.Version
+		if err = mutate(&deckItem); err != nil {
+			return DeckModel{}, err
+		}
+		deckItem.Version = readVersion + 1
+		deckItem.LastActivity = activityTime()
+		result, err := d.Collection.ReplaceOne(ctx, versionFilter(uuid, readVersion), deckItem)
+		if err != nil {
+			return DeckModel{}, err
+		}
+		if result.MatchedCount == 1 {
+			return deckItem, nil
+		}
+	}
+	return DeckModel{}, ErrUpdateConflict
+}
+
+// versionFilter matches the deck with this uuid while it is still at version.
+// Decks stored before versions were introduced have no version field and read
+// as version 0, so version 0 also matches a missing or null field.
+func versionFilter(uuid string, version int64) bson.D {
+	if version == 0 {
+		return bson.D{{Key: "uuid", Value: uuid}, {Key: "version", Value: bson.D{{Key: "$in", Value: bson.A{int64(0), nil}}}}}
+	}
+	return bson.D{{Key: "uuid", Value: uuid}, {Key: "version", Value: version}}
+}
+
+// DeleteDeckByUUID removes the deck. It returns mongo.ErrNoDocuments if there
+// is no deck with this uuid.
+func (d *DeckCRUDOperator) DeleteDeckByUUID(ctx context.Context, uuid string) error {
+	filterByUUID := bson.D{{Key: "uuid", Value: uuid}}
+	result, err := d.Collection.DeleteOne(ctx, filterByUUID)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// DrawCardsByUUID removes the cards described by opts from the deck and
+// returns them along with the updated deck. Each card is handed out exactly
+// once, even when called concurrently. Unless ifVersion is AnyVersion the draw
+// only happens while the deck is still at that version, otherwise
+// ErrVersionMismatch is returned.
+func DrawCardsByUUID(ctx context.Context, dc DeckCRUDer, uuid string, opts *deck.DrawOpts, ifVersion int64) (deck.Deck, DeckModel, error) {
+	var drawnCards deck.Deck
+	updated, err := dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		src, err := drawSource(d, opts)
+		if err != nil {
+			return err
+		}
+		drawn, remaining, err := deck.Draw(d.Cards, opts, src)
+		if err != nil {
+			return err
+		}
+		drawnCards, d.Cards = drawn, remaining
+		d.Drawn = append(d.Drawn, drawn...)
+		return nil
+	})
+	return drawnCards, updated, err
+}
+
+// ReturnCardsByUUID puts cards that were drawn or moved to a pile back at the
+// bottom of the deck. If no card codes are given, every drawn card and every
+// pile is returned. Listed cards are taken from the drawn cards first and
+// then from the piles in name order.
+func ReturnCardsByUUID(ctx context.Context, dc DeckCRUDer, uuid string, cardCodes []string, ifVersion int64) (deck.Deck, DeckModel, error) {
+	var returnedCards deck.Deck
+	updated, err := dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		returned := deck.Deck{}
+		if len(cardCodes) == 0 {
+			returned = append(returned, d.Drawn...)
+			for _, name := range sortedPileNames(d.Piles) {
+				returned = append(returned, d.Piles[name]...)
+			}
+			d.Drawn, d.Piles = deck.Deck{}, nil
+		}
+		for _, cardCode := range cardCodes {
+			card, err := takeDealtCard(d, cardCode)
+			if err != nil {
+				return err
+			}
+			returned = append(returned, card)
+		}
+		returnedCards = returned
+		d.Cards = append(d.Cards, returned...)
+		return nil
+	})
+	return returnedCards, updated, err
+}
+
+// takeDealtCard removes one copy of the card with the given code from the
+// drawn cards or, failing that, from the first pile holding it.
+func takeDealtCard(d *DeckModel, cardCode string) (deck.Card, error) {
+	taken, remaining, err := deck.DrawSpecificCards(d.Drawn, []string{cardCode})
+	if err == nil {
+		d.Drawn = remaining
+		return taken[0], nil
+	}
+	notInDeck, ok := err.(deck.ErrCardNotInDeck)
+	if !ok {
+		return deck.Card{}, err
+	}
+	for _, name := range sortedPileNames(d.Piles) {
+		taken, remaining, err = deck.DrawSpecificCards(d.Piles[name], []string{cardCode})
+		if err == nil {
+			d.Piles[name] = remaining
+			return taken[0], nil
+		}
+	}
+	return deck.Card{}, ErrCardNotDrawn{CardCode: notInDeck.CardCode}
+}
+
+func sortedPileNames(piles map[string]deck.Deck) []string {
+	names := make([]string, 0, len(piles))
+	for name := range piles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ShuffleByUUID shuffles the cards remaining in the deck passes times with
+// the given method. Drawn cards and piles are left as they are.
+func ShuffleByUUID(ctx context.Context, dc DeckCRUDer, uuid string, method deck.ShuffleMethod, passes int, ifVersion int64) (DeckModel, error) {
+	return dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		src, err := deckSource(d)
+		if err != nil {
+			return err
+		}
+		if err = d.Cards.ShuffleBy(method, passes, src); err != nil {
+			return err
+		}
+		d.Shuffled = true
+		return nil
+	})
+}
+
+// CutByUUID moves the top position cards of the deck to its bottom.
+func CutByUUID(ctx context.Context, dc DeckCRUDer, uuid string, position int, ifVersion int64) (DeckModel, error) {
+	return dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		if d.RNG == deck.RNGFair {
+			return deck.ErrFairReshuffle
+		}
+		return d.Cards.Cut(position)
+	})
+}
+
+// deckSource returns the Source used to reshuffle d or draw from it at
+// random. For seeded decks it is seeded from the deck's seed and its current
+// version, so every operation on the deck stays reproducible.
+func deckSource(d *DeckModel) (deck.Source, error) {
+	var seed *int64
+	if d.Seed != nil {
+		versionSeed := *d.Seed + d.Version
+		seed = &versionSeed
+	}
+	return deck.NewSource(d.RNG, seed)
+}
+
+// drawSource returns the Source needed to draw the cards described by opts
+// from d, or nil if the draw is not random.
+func drawSource(d *DeckModel, opts *deck.DrawOpts) (deck.Source, error) {
+	if len(opts.Cards) > 0 || opts.Mode != deck.DrawRandom {
+		return nil, nil
+	}
+	if d.RNG == deck.RNGFair {
+		return nil, deck.ErrFairRandomDraw
+	}
+	return deckSource(d)
+}
+
+// CloseByUUID closes the deck, after which it can no longer be changed. Fair
+// decks can be closed before they are seeded, to abandon them.
+func CloseByUUID(ctx context.Context, dc DeckCRUDer, uuid string, ifVersion int64) (DeckModel, error) {
+	return dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkCurrent(d, ifVersion); err != nil {
+			return err
+		}
+		d.Closed = true
+		return nil
+	})
+}
+
+// SeedFairDeckByUUID mixes the players' clientSeed into the shuffle of a
+// fair deck and shuffles it. The server seed was fixed and its commitment
+// published when the deck was created, before the server could know
+// clientSeed, so neither side can choose the order on its own.
+func SeedFairDeckByUUID(ctx context.Context, dc DeckCRUDer, uuid string, clientSeed string, ifVersion int64) (DeckModel, error) {
+	if clientSeed == "" {
+		return DeckModel{}, deck.ErrClientSeedMissing
+	}
+	return dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkCurrent(d, ifVersion); err != nil {
+			return err
+		}
+		if d.Fair == nil {
+			return ErrNotFairDeck
+		}
+		if d.Fair.ClientSeed != "" {
+			return ErrFairDeckSeeded
+		}
+		// Nothing can change an unseeded deck, so its cards are still the
+		// unshuffled ones the proof starts from.
+		d.Cards.Shuffle(deck.NewFairSource(d.Fair.ServerSeed, clientSeed))
+		d.Original = append(deck.Deck{}, d.Cards...)
+		d.Fair.ClientSeed = clientSeed
+		d.Shuffled = true
+		d.ShuffleMethod, d.Passes = deck.ShuffleRandom, 1
+		return nil
+	})
+}
+
+// checkWritable fails like checkCurrent, or with ErrFairDeckNotSeeded if d is
+// a fair deck that has not been shuffled yet.
+func checkWritable(d *DeckModel, ifVersion int64) error {
+	if err := checkCurrent(d, ifVersion); err != nil {
+		return err
+	}
+	if d.Fair != nil && d.Fair.ClientSeed == "" {
+		return ErrFairDeckNotSeeded
+	}
+	return nil
+}
+
+// checkCurrent fails with ErrDeckClosed if d is closed, or with
+// ErrVersionMismatch if ifVersion is not AnyVersion and is not the current
+// version of d.
+func checkCurrent(d *DeckModel, ifVersion int64) error {
+	if d.Closed {
+		return ErrDeckClosed
+	}
+	if ifVersion != AnyVersion && d.Version != ifVersion {
+		return ErrVersionMismatch
+	}
+	return nil
 }
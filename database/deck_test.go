@@ -0,0 +1,311 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func getTestDeck() deck.Deck {
+	return deck.Deck{
+		{Value: deck.Ace, Suit: deck.Spades},
+		{Value: deck.Two, Suit: deck.Spades},
+		{Value: deck.Three, Suit: deck.Spades},
+		{Value: deck.Four, Suit: deck.Spades},
+		{Value: deck.Five, Suit: deck.Spades},
+	}
+}
+
+func TestReturnCardsByUUID(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards := getTestDeck()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Original: cards})
+	DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 2}, AnyVersion)
+	AddToPileByUUID(ctx, store, "test-uuid-123", "discard", []string{"4S"}, 0, AnyVersion)
+
+	returned, updated, err := ReturnCardsByUUID(ctx, store, "test-uuid-123", []string{"4S", "AS"}, AnyVersion)
+	if err != nil {
+		t.Errorf("Failed for returning specific cards: expected error to be %v, got %v", nil, err)
+	}
+	expectedReturned := deck.Deck{{Value: deck.Four, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Spades}}
+	if !cmp.Equal(returned, expectedReturned) {
+		t.Errorf("Failed for returning specific cards: expected returned cards to be %v, got %v", expectedReturned, returned)
+	}
+	expectedCards := deck.Deck{
+		{Value: deck.Three, Suit: deck.Spades},
+		{Value: deck.Five, Suit: deck.Spades},
+		{Value: deck.Four, Suit: deck.Spades},
+		{Value: deck.Ace, Suit: deck.Spades},
+	}
+	if !cmp.Equal(updated.Cards, expectedCards) {
+		t.Errorf("Failed for returning specific cards: expected deck to be %v, got %v", expectedCards, updated.Cards)
+	}
+
+	_, _, err = ReturnCardsByUUID(ctx, store, "test-uuid-123", []string{"3S"}, AnyVersion)
+	if !cmp.Equal(err, ErrCardNotDrawn{CardCode: "3S"}) {
+		t.Errorf("Failed for returning undrawn card: expected error to be %v, got %v", ErrCardNotDrawn{CardCode: "3S"}, err)
+	}
+
+	AddToPileByUUID(ctx, store, "test-uuid-123", "player1", nil, 1, AnyVersion)
+	_, updated, err = ReturnCardsByUUID(ctx, store, "test-uuid-123", nil, AnyVersion)
+	if err != nil {
+		t.Errorf("Failed for returning all cards: expected error to be %v, got %v", nil, err)
+	}
+	if len(updated.Cards) != len(cards) || len(updated.Drawn) != 0 || len(updated.Piles) != 0 {
+		t.Errorf("Failed for returning all cards: expected every card back in the deck, got %v", updated)
+	}
+}
+
+func TestShuffleByUUID(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards := getTestDeck()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Original: cards})
+	DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 2}, AnyVersion)
+
+	updated, err := ShuffleByUUID(ctx, store, "test-uuid-123", deck.ShuffleRiffle, 3, AnyVersion)
+	if err != nil {
+		t.Errorf("Failed for shuffle: expected error to be %v, got %v", nil, err)
+	}
+	if !updated.Shuffled {
+		t.Errorf("Failed for shuffle: expected deck to be marked shuffled")
+	}
+	counts := make(map[deck.Card]int)
+	for _, card := range updated.Cards {
+		counts[card]++
+	}
+	for _, card := range cards[2:] {
+		if counts[card] != 1 {
+			t.Errorf("Failed for shuffle: expected remaining cards to be %v, got %v", cards[2:], updated.Cards)
+		}
+	}
+	if !cmp.Equal(updated.Drawn, cards[:2]) {
+		t.Errorf("Failed for shuffle: expected drawn cards to be %v, got %v", cards[:2], updated.Drawn)
+	}
+}
+
+func TestCutByUUID(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards := getTestDeck()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Original: cards})
+
+	updated, err := CutByUUID(ctx, store, "test-uuid-123", 2, AnyVersion)
+	if err != nil {
+		t.Errorf("Failed for cut: expected error to be %v, got %v", nil, err)
+	}
+	expectedCards := append(append(deck.Deck{}, cards[2:]...), cards[:2]...)
+	if !cmp.Equal(updated.Cards, expectedCards) {
+		t.Errorf("Failed for cut: expected deck to be %v, got %v", expectedCards, updated.Cards)
+	}
+
+	_, err = CutByUUID(ctx, store, "test-uuid-123", 6, AnyVersion)
+	expectedErr := deck.ErrInvalidCutPosition{Position: 6, Size: 5}
+	if !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for invalid cut: expected error to be %v, got %v", expectedErr, err)
+	}
+}
+
+func TestDrawCardsByUUIDRandomIsReproducible(t *testing.T) {
+	ctx := context.TODO()
+	seed := int64(99)
+	var draws []deck.Deck
+	for i := 0; i < 2; i++ {
+		store := NewMemoryDeckStore()
+		cards := getTestDeck()
+		store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Original: cards, Seed: &seed})
+		drawn, updated, err := DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{Mode: deck.DrawRandom, NumberOfCards: 3}, AnyVersion)
+		if err != nil {
+			t.Errorf("Failed for random draw: expected error to be %v, got %v", nil, err)
+		}
+		if !cmp.Equal(updated.Drawn, drawn) || len(updated.Cards) != 2 {
+			t.Errorf("Failed for random draw: expected drawn cards to be recorded, got %v", updated)
+		}
+		draws = append(draws, drawn)
+	}
+	if !cmp.Equal(draws[0], draws[1]) {
+		t.Errorf("Failed for random draw: expected the same seed to draw the same cards, got %v and %v", draws[0], draws[1])
+	}
+}
+
+func TestCloseByUUID(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards := getTestDeck()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Original: cards, Version: 1})
+
+	updated, err := CloseByUUID(ctx, store, "test-uuid-123", 1)
+	if err != nil || !updated.Closed {
+		t.Errorf("Failed for close: expected a closed deck and no error, got %v and %v", updated, err)
+	}
+	if _, _, err = DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != ErrDeckClosed {
+		t.Errorf("Failed for draw from closed deck: expected error to be %v, got %v", ErrDeckClosed, err)
+	}
+	if _, _, err = AddToPileByUUID(ctx, store, "test-uuid-123", "discard", nil, 1, AnyVersion); err != ErrDeckClosed {
+		t.Errorf("Failed for add to pile of closed deck: expected error to be %v, got %v", ErrDeckClosed, err)
+	}
+	if _, err = ShuffleByUUID(ctx, store, "test-uuid-123", deck.ShuffleRandom, 1, AnyVersion); err != ErrDeckClosed {
+		t.Errorf("Failed for shuffle of closed deck: expected error to be %v, got %v", ErrDeckClosed, err)
+	}
+	found, _ := store.FindDeckByUUID(ctx, "test-uuid-123")
+	if !cmp.Equal(found.Cards, cards) {
+		t.Errorf("Failed for closed deck: expected cards to be unchanged, got %v", found.Cards)
+	}
+}
+
+func TestSeedFairDeckByUUID(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards, _ := deck.New(&deck.NewDeckOpts{})
+	serverSeed := deck.NewServerSeed()
+	fair := &FairShuffleModel{ServerSeed: serverSeed, Commitment: deck.Commitment(serverSeed), Unshuffled: cards}
+	store.InsertDeck(ctx, DeckModel{UUID: "fair-uuid", Cards: cards, Original: cards, RNG: deck.RNGFair, Fair: fair, Version: 1})
+	store.InsertDeck(ctx, DeckModel{UUID: "abandoned-uuid", Cards: cards, Original: cards, RNG: deck.RNGFair, Fair: fair, Version: 1})
+	store.InsertDeck(ctx, DeckModel{UUID: "math-uuid", Cards: cards, Original: cards, Version: 1})
+
+	if _, _, err := DrawCardsByUUID(ctx, store, "fair-uuid", &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != ErrFairDeckNotSeeded {
+		t.Errorf("Failed for draw before seeding: expected error to be %v, got %v", ErrFairDeckNotSeeded, err)
+	}
+	if _, _, err := AddToPileByUUID(ctx, store, "fair-uuid", "discard", nil, 1, AnyVersion); err != ErrFairDeckNotSeeded {
+		t.Errorf("Failed for add to pile before seeding: expected error to be %v, got %v", ErrFairDeckNotSeeded, err)
+	}
+	if _, err := SeedFairDeckByUUID(ctx, store, "fair-uuid", "", AnyVersion); err != deck.ErrClientSeedMissing {
+		t.Errorf("Failed for empty client seed: expected error to be %v, got %v", deck.ErrClientSeedMissing, err)
+	}
+
+	updated, err := SeedFairDeckByUUID(ctx, store, "fair-uuid", "player-one", 1)
+	if err != nil {
+		t.Fatalf("Failed for seeding: expected error to be %v, got %v", nil, err)
+	}
+	if !updated.Shuffled || updated.Fair.ClientSeed != "player-one" || updated.Version != 2 {
+		t.Errorf("Failed for seeding: expected a shuffled deck with the client seed at version 2, got %+v", updated)
+	}
+	if !deck.VerifyFairShuffle(serverSeed, fair.Commitment, "player-one", cards, updated.Cards) || !cmp.Equal(updated.Original, updated.Cards) {
+		t.Errorf("Failed for seeding: expected the shuffle to verify")
+	}
+	if _, err = SeedFairDeckByUUID(ctx, store, "fair-uuid", "player-two", AnyVersion); err != ErrFairDeckSeeded {
+		t.Errorf("Failed for seeding twice: expected error to be %v, got %v", ErrFairDeckSeeded, err)
+	}
+	if _, _, err = DrawCardsByUUID(ctx, store, "fair-uuid", &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != nil {
+		t.Errorf("Failed for draw after seeding: expected error to be %v, got %v", nil, err)
+	}
+
+	if _, err = SeedFairDeckByUUID(ctx, store, "math-uuid", "player-one", AnyVersion); err != ErrNotFairDeck {
+		t.Errorf("Failed for seeding a deck that is not fair: expected error to be %v, got %v", ErrNotFairDeck, err)
+	}
+	if closed, err := CloseByUUID(ctx, store, "abandoned-uuid", AnyVersion); err != nil || !closed.Closed {
+		t.Errorf("Failed for closing an unseeded deck: expected a closed deck and no error, got %v and %v", closed.Closed, err)
+	}
+}
+
+// mongoDeckDocument returns d the way MongoDB stores it, without its version
+// field if d was stored before versions were introduced.
+func mongoDeckDocument(t *testing.T, d DeckModel, legacy bool) bson.D {
+	raw, err := bson.Marshal(d)
+	if err != nil {
+		t.Fatalf("Failed to marshal deck: %v", err)
+	}
+	var doc bson.D
+	if err = bson.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("Failed to unmarshal deck: %v", err)
+	}
+	if legacy {
+		for i, e := range doc {
+			if e.Key == "version" {
+				doc = append(doc[:i], doc[i+1:]...)
+				break
+			}
+		}
+	}
+	return doc
+}
+
+func findResponse(doc bson.D) bson.D {
+	return mtest.CreateCursorResponse(0, "cardsdb.decks", mtest.FirstBatch, doc)
+}
+
+func replaceResponse(matched int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: matched}, bson.E{Key: "nModified", Value: matched})
+}
+
+// replaceFilters returns the filters of the replace commands sent to the mock
+// deployment.
+func replaceFilters(mt *mtest.T) []bson.Raw {
+	var filters []bson.Raw
+	for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+		if event.CommandName == "update" {
+			update := event.Command.Lookup("updates").Array().Index(0).Value().Document()
+			filters = append(filters, update.Lookup("q").Document())
+		}
+	}
+	return filters
+}
+
+func TestDeckCRUDOperatorUpdateDeckByUUID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	drawTwo := func(d *DeckModel) error {
+		d.Cards = d.Cards[2:]
+		return nil
+	}
+	stored := DeckModel{UUID: "test-uuid-123", Cards: getTestDeck(), Version: 3}
+
+	mt.Run("legacy deck without version", func(mt *mtest.T) {
+		legacy := stored
+		legacy.Version = 0
+		mt.AddMockResponses(findResponse(mongoDeckDocument(t, legacy, true)), replaceResponse(1))
+		dc := DeckCRUDOperator{Collection: mt.Coll}
+		updated, err := dc.UpdateDeckByUUID(context.TODO(), "test-uuid-123", drawTwo)
+		if err != nil {
+			mt.Fatalf("Expected error to be %v, got %v", nil, err)
+		}
+		if updated.Version != 1 || len(updated.Cards) != 3 {
+			mt.Errorf("Expected version 1 with 3 cards, got version %d with %d cards", updated.Version, len(updated.Cards))
+		}
+		filters := replaceFilters(mt)
+		if len(filters) != 1 {
+			mt.Fatalf("Expected 1 replace, got %d", len(filters))
+		}
+		expected, _ := bson.Marshal(bson.D{{Key: "$in", Value: bson.A{int64(0), nil}}})
+		if version := filters[0].Lookup("version").Document(); !bytes.Equal(version, expected) {
+			mt.Errorf("Expected the version filter to be %v, got %v", bson.Raw(expected), version)
+		}
+	})
+
+	mt.Run("retries on conflict", func(mt *mtest.T) {
+		changed := stored
+		changed.Version = 4
+		mt.AddMockResponses(
+			findResponse(mongoDeckDocument(t, stored, false)), replaceResponse(0),
+			findResponse(mongoDeckDocument(t, changed, false)), replaceResponse(1),
+		)
+		dc := DeckCRUDOperator{Collection: mt.Coll}
+		updated, err := dc.UpdateDeckByUUID(context.TODO(), "test-uuid-123", drawTwo)
+		if err != nil {
+			mt.Fatalf("Expected error to be %v, got %v", nil, err)
+		}
+		if updated.Version != 5 {
+			mt.Errorf("Expected version 5, got %d", updated.Version)
+		}
+		filters := replaceFilters(mt)
+		if len(filters) != 2 || filters[0].Lookup("version").Int64() != 3 || filters[1].Lookup("version").Int64() != 4 {
+			mt.Errorf("Expected replaces guarded by versions 3 and 4, got %v", filters)
+		}
+	})
+
+	mt.Run("gives up after too many conflicts", func(mt *mtest.T) {
+		for i := 0; i < maxUpdateRetries; i++ {
+			mt.AddMockResponses(findResponse(mongoDeckDocument(t, stored, false)), replaceResponse(0))
+		}
+		dc := DeckCRUDOperator{Collection: mt.Coll}
+		if _, err := dc.UpdateDeckByUUID(context.TODO(), "test-uuid-123", drawTwo); err != ErrUpdateConflict {
+			mt.Errorf("Expected error to be %v, got %v", ErrUpdateConflict, err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SweepInterval is how often idle decks are purged from stores without a
+// TTL index. MongoDB checks its TTL indexes just as often.
+const SweepInterval = time.Minute
+
+// MaxTTL is the longest TTL of an ExpiringDeckStore. MongoDB takes the purge
+// delay of the TTL index, twice the TTL, in 32 bit seconds.
+const MaxTTL = math.MaxInt32 / 2 * time.Second
+
+const expiryIndexName = "last_activity_ttl"
+
+// indexOptionsConflict is the MongoDB error code for an index that exists
+// with the same name but different options.
+const indexOptionsConflict = 85
+
+var ErrDeckExpired = errors.New("deck has expired")
+
+// IdleDeckPurger is implemented by stores that can delete idle decks
+// themselves, as opposed to MongoDB which does it with a TTL index.
+type IdleDeckPurger interface {
+	PurgeDecksIdleSince(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// ExpiringDeckStore wraps a DeckCRUDer so that decks idle for longer than TTL
+// are expired: reading or changing them fails with ErrDeckExpired, and they
+// are not listed. Expired decks are only deleted once they have been idle for
+// twice the TTL, so for a while clients can tell an expired deck from one
+// that never existed.
+type ExpiringDeckStore struct {
+	DeckCRUDer
+	TTL time.Duration
+	now func() time.Time
+}
+
+func NewExpiringDeckStore(dc DeckCRUDer, ttl time.Duration) *ExpiringDeckStore {
+	return &ExpiringDeckStore{DeckCRUDer: dc, TTL: ttl, now: time.Now}
+}
+
+func (e *ExpiringDeckStore) FindDeckByUUID(ctx context.Context, uuid string) (DeckModel, error) {
+	deckItem, err := e.DeckCRUDer.FindDeckByUUID(ctx, uuid)
+	if err == nil && e.expired(deckItem) {
+		return DeckModel{}, ErrDeckExpired
+	}
+	return deckItem, err
+}
+
+func (e *ExpiringDeckStore) UpdateDeckByUUID(ctx context.Context, uuid string, mutate DeckMutation) (DeckModel, error) {
+	return e.DeckCRUDer.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if e.expired(*d) {
+			return ErrDeckExpired
+		}
+		return mutate(d)
+	})
+}
+
+// DeleteDeckByUUID fails with ErrDeckExpired for expired decks, which are left
+// for the purge to delete.
+func (e *ExpiringDeckStore) DeleteDeckByUUID(ctx context.Context, uuid string) error {
+	if _, err := e.FindDeckByUUID(ctx, uuid); err != nil {
+		return err
+	}
+	return e.DeckCRUDer.DeleteDeckByUUID(ctx, uuid)
+}
+
+// ListDecks leaves expired decks out of the listing. Pages are refilled from
+// the decks that follow, so a page is only short if it is the last one.
+func (e *ExpiringDeckStore) ListDecks(ctx context.Context, opts ListDecksOpts) (DeckPage, error) {
+	result := DeckPage{Decks: []DeckModel{}}
+	limit := opts.Limit
+	for {
+		page, err := e.DeckCRUDer.ListDecks(ctx, opts)
+		if err != nil {
+			return DeckPage{}, err
+		}
+		for _, d := range page.Decks {
+			if !e.expired(d) {
+				result.Decks = append(result.Decks, d)
+			}
+		}
+		result.NextCursor = page.NextCursor
+		if page.NextCursor == "" || len(result.Decks) >= limit {
+			return result, nil
+		}
+		opts.After, opts.Limit = page.NextCursor, limit-len(result.Decks)
+	}
+}
+
+func (e *ExpiringDeckStore) expired(d DeckModel) bool {
+	return isIdleSince(d, e.now().Add(-e.TTL))
+}
+
+// PurgeAfter is how long a deck has to be idle before it is deleted.
+func (e *ExpiringDeckStore) PurgeAfter() time.Duration {
+	return 2 * e.TTL
+}
+
+// RunSweeper deletes decks idle for longer than PurgeAfter from purger every
+// SweepInterval, until ctx is done.
+func (e *ExpiringDeckStore) RunSweeper(ctx context.Context, purger IdleDeckPurger) {
+	ticker := time.NewTicker(SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := purger.PurgeDecksIdleSince(ctx, e.now().Add(-e.PurgeAfter()))
+			if err != nil {
+				log.Println("Error occurred while purging idle decks.", err)
+			} else if purged > 0 {
+				log.Println("Purged idle decks:", purged)
+			}
+		}
+	}
+}
+
+// EnsureExpiryIndex creates a TTL index that makes MongoDB delete decks idle
+// for longer than purgeAfter, replacing the index if it was created with a
+// different duration.
+func (d *DeckCRUDOperator) EnsureExpiryIndex(ctx context.Context, purgeAfter time.Duration) error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "last_activity", Value: 1}},
+		Options: options.Index().SetName(expiryIndexName).SetExpireAfterSeconds(int32(purgeAfter.Seconds())),
+	}
+	indexes := d.Collection.Indexes()
+	_, err := indexes.CreateOne(ctx, index)
+	var serverErr mongo.ServerError
+	if err == nil || !errors.As(err, &serverErr) || !serverErr.HasErrorCode(indexOptionsConflict) {
+		return err
+	}
+	if _, err := indexes.DropOne(ctx, expiryIndexName); err != nil {
+		return err
+	}
+	_, err = indexes.CreateOne(ctx, index)
+	return err
+}
+
+func isIdleSince(d DeckModel, cutoff time.Time) bool {
+	return !d.LastActivity.IsZero() && d.LastActivity.Before(cutoff)
+}
+
+// activityTime returns the current time as it is stored in BSON, to the
+// millisecond, so a deck returned by an update equals the deck read back.
+func activityTime() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,168 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestExpiringDeckStore(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	cards := deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Two, Suit: deck.Spades}}
+	backing := NewMemoryDeckStore()
+	backing.InsertDeck(ctx, DeckModel{UUID: "active", Cards: cards, LastActivity: now.Add(-time.Minute)})
+	backing.InsertDeck(ctx, DeckModel{UUID: "idle", Cards: cards, LastActivity: now.Add(-2 * time.Hour)})
+	backing.InsertDeck(ctx, DeckModel{UUID: "legacy", Cards: cards})
+	store := &ExpiringDeckStore{DeckCRUDer: backing, TTL: time.Hour, now: func() time.Time { return now }}
+
+	tests := []struct {
+		name        string
+		uuid        string
+		expectedErr error
+	}{
+		{name: "active", uuid: "active", expectedErr: nil},
+		{name: "idle", uuid: "idle", expectedErr: ErrDeckExpired},
+		{name: "no activity recorded", uuid: "legacy", expectedErr: nil},
+		{name: "missing", uuid: "missing", expectedErr: mongo.ErrNoDocuments},
+	}
+	for _, test := range tests {
+		if _, err := store.FindDeckByUUID(ctx, test.uuid); err != test.expectedErr {
+			t.Errorf("Failed for find %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if _, _, err := DrawCardsByUUID(ctx, store, test.uuid, &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != test.expectedErr {
+			t.Errorf("Failed for draw %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+
+	found, _ := backing.FindDeckByUUID(ctx, "idle")
+	if len(found.Cards) != len(cards) {
+		t.Errorf("Failed for expired deck: expected cards to be unchanged, got %v", found.Cards)
+	}
+
+	for _, test := range tests {
+		if err := store.DeleteDeckByUUID(ctx, test.uuid); err != test.expectedErr {
+			t.Errorf("Failed for delete %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+	if _, err := backing.FindDeckByUUID(ctx, "idle"); err != nil {
+		t.Errorf("Failed for expired deck: expected it to be left for the purge, got error %v", err)
+	}
+}
+
+func TestExpiringDeckStoreListDecks(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	backing := NewMemoryDeckStore()
+	store := &ExpiringDeckStore{DeckCRUDer: backing, TTL: time.Hour, now: func() time.Time { return now }}
+	idle := map[string]bool{"b": true, "c": true, "d": true, "f": true}
+	for i, uuid := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		lastActivity := now.Add(-time.Minute)
+		if idle[uuid] {
+			lastActivity = now.Add(-2 * time.Hour)
+		}
+		backing.InsertDeck(ctx, DeckModel{UUID: uuid, CreatedAt: now.Add(time.Duration(i-10) * time.Minute), LastActivity: lastActivity})
+	}
+
+	expectedPages := [][]string{{"a", "e"}, {"g"}}
+	opts := ListDecksOpts{Limit: 2}
+	for i, expected := range expectedPages {
+		page, err := store.ListDecks(ctx, opts)
+		if err != nil {
+			t.Fatalf("Failed for page %d: expected error to be %v, got %v", i, nil, err)
+		}
+		uuids := []string{}
+		for _, d := range page.Decks {
+			uuids = append(uuids, d.UUID)
+		}
+		if !cmp.Equal(uuids, expected) {
+			t.Errorf("Failed for page %d: expected decks to be %v, got %v", i, expected, uuids)
+		}
+		if last := i == len(expectedPages)-1; last != (page.NextCursor == "") {
+			t.Errorf("Failed for page %d: expected a next cursor: %v, got %q", i, !last, page.NextCursor)
+		}
+		opts.After = page.NextCursor
+	}
+}
+
+func TestDeckCRUDOperatorEnsureExpiryIndex(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+	success := mtest.CreateSuccessResponse()
+	optionsConflict := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 85, Name: "IndexOptionsConflict", Message: "an index with the same name already exists with different options"})
+	unauthorized := mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 13, Name: "Unauthorized", Message: "not authorized"})
+
+	tests := []struct {
+		name             string
+		responses        []bson.D
+		expectedCommands []string
+		expectErr        bool
+	}{
+		{name: "created", responses: []bson.D{success}, expectedCommands: []string{"createIndexes"}},
+		{name: "options conflict", responses: []bson.D{optionsConflict, success, success}, expectedCommands: []string{"createIndexes", "dropIndexes", "createIndexes"}},
+		{name: "other error", responses: []bson.D{unauthorized}, expectedCommands: []string{"createIndexes"}, expectErr: true},
+	}
+	for _, test := range tests {
+		mt.Run(test.name, func(mt *mtest.T) {
+			mt.AddMockResponses(test.responses...)
+			dc := DeckCRUDOperator{Collection: mt.Coll}
+			err := dc.EnsureExpiryIndex(context.TODO(), 2*time.Hour)
+			if (err != nil) != test.expectErr {
+				mt.Errorf("Failed for %s case: expected error to be returned: %v, got %v", test.name, test.expectErr, err)
+			}
+			var commands []string
+			for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+				commands = append(commands, event.CommandName)
+			}
+			if !cmp.Equal(commands, test.expectedCommands) {
+				mt.Errorf("Failed for %s case: expected commands to be %v, got %v", test.name, test.expectedCommands, commands)
+			}
+		})
+	}
+}
+
+func TestPurgeDecksIdleSince(t *testing.T) {
+	ctx := context.TODO()
+	cutoff := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	bolt, err := OpenBoltDeckStore(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	defer bolt.Close()
+
+	stores := []struct {
+		name  string
+		store interface {
+			DeckCRUDer
+			IdleDeckPurger
+		}
+	}{
+		{name: "memory", store: NewMemoryDeckStore()},
+		{name: "bolt", store: bolt},
+	}
+	for _, test := range stores {
+		test.store.InsertDeck(ctx, DeckModel{UUID: "active", LastActivity: cutoff.Add(time.Minute)})
+		test.store.InsertDeck(ctx, DeckModel{UUID: "idle", LastActivity: cutoff.Add(-time.Minute)})
+		test.store.InsertDeck(ctx, DeckModel{UUID: "legacy"})
+
+		purged, err := test.store.PurgeDecksIdleSince(ctx, cutoff)
+		if err != nil || purged != 1 {
+			t.Errorf("Failed for %s store: expected 1 deck purged and error to be %v, got %d and %v", test.name, nil, purged, err)
+		}
+		if _, err := test.store.FindDeckByUUID(ctx, "idle"); err != mongo.ErrNoDocuments {
+			t.Errorf("Failed for %s store: expected idle deck to be deleted, got error %v", test.name, err)
+		}
+		for _, uuid := range []string{"active", "legacy"} {
+			if _, err := test.store.FindDeckByUUID(ctx, uuid); err != nil {
+				t.Errorf("Failed for %s store: expected %s deck to be kept, got error %v", test.name, uuid, err)
+			}
+		}
+	}
+}
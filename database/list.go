@@ -0,0 +1,184 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ErrInvalidCursor = errors.New("cursor is malformed")
+
+// DeckFilter selects the decks to list. Zero fields match every deck.
+type DeckFilter struct {
+	CreatedAfter time.Time
+	// RemainingBelow matches decks with fewer cards remaining than it.
+	RemainingBelow *int
+	Owner          string
+	Tag            string
+}
+
+// ListDecksOpts describes one page of decks. Decks are listed oldest first,
+// and After is the NextCursor of the previous page, if any.
+type ListDecksOpts struct {
+	Filter DeckFilter
+	Limit  int
+	After  string
+}
+
+type DeckPage struct {
+	Decks []DeckModel
+	// NextCursor is empty if this is the last page.
+	NextCursor string
+}
+
+func (f DeckFilter) matches(d DeckModel) bool {
+	if !f.CreatedAfter.IsZero() && !d.CreatedAt.After(f.CreatedAfter) {
+		return false
+	}
+	if f.RemainingBelow != nil && len(d.Cards) >= *f.RemainingBelow {
+		return false
+	}
+	if f.Owner != "" && d.Owner != f.Owner {
+		return false
+	}
+	if f.Tag != "" && !containsString(d.Tags, f.Tag) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// deckCursor is the position of a deck in the listing order.
+type deckCursor struct {
+	CreatedAt time.Time
+	UUID      string
+}
+
+func cursorOf(d DeckModel) deckCursor {
+	return deckCursor{CreatedAt: d.CreatedAt, UUID: d.UUID}
+}
+
+func (c deckCursor) before(d DeckModel) bool {
+	if !c.CreatedAt.Equal(d.CreatedAt) {
+		return c.CreatedAt.Before(d.CreatedAt)
+	}
+	return c.UUID < d.UUID
+}
+
+func (c deckCursor) encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + c.UUID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (deckCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return deckCursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), " ", 2)
+	if len(parts) != 2 {
+		return deckCursor{}, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return deckCursor{}, ErrInvalidCursor
+	}
+	return deckCursor{CreatedAt: createdAt, UUID: parts[1]}, nil
+}
+
+// pageOf returns the page described by opts from decks, which are filtered
+// and sorted here. It is used by the stores that cannot query their decks.
+func pageOf(decks []DeckModel, opts ListDecksOpts) (DeckPage, error) {
+	var after *deckCursor
+	if opts.After != "" {
+		c, err := decodeCursor(opts.After)
+		if err != nil {
+			return DeckPage{}, err
+		}
+		after = &c
+	}
+	matching := []DeckModel{}
+	for _, d := range decks {
+		if opts.Filter.matches(d) && (after == nil || after.before(d)) {
+			matching = append(matching, d)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return cursorOf(matching[i]).before(matching[j])
+	})
+	return limitPage(matching, opts.Limit), nil
+}
+
+// limitPage cuts sorted decks down to limit, setting the cursor if any
+// were left out.
+func limitPage(decks []DeckModel, limit int) DeckPage {
+	if limit <= 0 || len(decks) <= limit {
+		return DeckPage{Decks: decks}
+	}
+	decks = decks[:limit]
+	return DeckPage{Decks: decks, NextCursor: cursorOf(decks[limit-1]).encode()}
+}
+
+// ListDecks returns one page of the decks matching opts.Filter.
+func (d *DeckCRUDOperator) ListDecks(ctx context.Context, opts ListDecksOpts) (DeckPage, error) {
+	filter := bson.D{}
+	if !opts.Filter.CreatedAfter.IsZero() {
+		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$gt", Value: opts.Filter.CreatedAfter}}})
+	}
+	if opts.Filter.RemainingBelow != nil {
+		remaining := bson.D{{Key: "$size", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$cards", bson.A{}}}}}}
+		filter = append(filter, bson.E{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{remaining, *opts.Filter.RemainingBelow}}}})
+	}
+	if opts.Filter.Owner != "" {
+		filter = append(filter, bson.E{Key: "owner", Value: opts.Filter.Owner})
+	}
+	if opts.Filter.Tag != "" {
+		filter = append(filter, bson.E{Key: "tags", Value: opts.Filter.Tag})
+	}
+	if opts.After != "" {
+		after, err := decodeCursor(opts.After)
+		if err != nil {
+			return DeckPage{}, err
+		}
+		sameCreatedAt := bson.E{Key: "created_at", Value: after.CreatedAt}
+		if after.CreatedAt.IsZero() {
+			// Decks stored before creation times were recorded have none,
+			// and are listed first. A null match finds them, as $gt and an
+			// equal date never match a missing field.
+			sameCreatedAt.Value = bson.D{{Key: "$in", Value: bson.A{nil, after.CreatedAt}}}
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: "created_at", Value: bson.D{{Key: "$gt", Value: after.CreatedAt}}}},
+			bson.D{sameCreatedAt, {Key: "uuid", Value: bson.D{{Key: "$gt", Value: after.UUID}}}},
+		}})
+	}
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "uuid", Value: 1}})
+	if opts.Limit > 0 {
+		// One extra deck tells whether there is another page.
+		findOpts.SetLimit(int64(opts.Limit) + 1)
+	}
+	cursor, err := d.Collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return DeckPage{}, err
+	}
+	decks := []DeckModel{}
+	if err = cursor.All(ctx, &decks); err != nil {
+		return DeckPage{}, err
+	}
+	return limitPage(decks, opts.Limit), nil
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func deckUUIDs(decks []DeckModel) []string {
+	uuids := []string{}
+	for _, d := range decks {
+		uuids = append(uuids, d.UUID)
+	}
+	return uuids
+}
+
+func TestListDecks(t *testing.T) {
+	ctx := context.TODO()
+	start := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	card := deck.Card{Value: deck.Ace, Suit: deck.Spades}
+	bolt, err := OpenBoltDeckStore(filepath.Join(t.TempDir(), "cards.db"))
+	if err != nil {
+		t.Fatalf("Failed to open bolt store: %v", err)
+	}
+	defer bolt.Close()
+
+	two, three := 2, 3
+	tests := []struct {
+		name     string
+		filter   DeckFilter
+		expected []string
+	}{
+		{name: "no filter", filter: DeckFilter{}, expected: []string{"a", "b", "c", "d"}},
+		{name: "created after", filter: DeckFilter{CreatedAfter: start.Add(time.Hour)}, expected: []string{"c", "d"}},
+		{name: "remaining below", filter: DeckFilter{RemainingBelow: &two}, expected: []string{"a", "c"}},
+		{name: "owner", filter: DeckFilter{Owner: "alice"}, expected: []string{"a", "b", "c"}},
+		{name: "tag", filter: DeckFilter{Tag: "poker"}, expected: []string{"b", "d"}},
+		{name: "combined", filter: DeckFilter{Owner: "alice", Tag: "poker", RemainingBelow: &three}, expected: []string{"b"}},
+	}
+	stores := []struct {
+		name  string
+		store DeckCRUDer
+	}{
+		{name: "memory", store: NewMemoryDeckStore()},
+		{name: "bolt", store: bolt},
+	}
+	for _, s := range stores {
+		s.store.InsertDeck(ctx, DeckModel{UUID: "d", CreatedAt: start.Add(2 * time.Hour), Cards: deck.Deck{card, card}, Owner: "bob", Tags: []string{"poker"}})
+		s.store.InsertDeck(ctx, DeckModel{UUID: "c", CreatedAt: start.Add(2 * time.Hour), Owner: "alice"})
+		s.store.InsertDeck(ctx, DeckModel{UUID: "b", CreatedAt: start.Add(time.Hour), Cards: deck.Deck{card, card}, Owner: "alice", Tags: []string{"blackjack", "poker"}})
+		s.store.InsertDeck(ctx, DeckModel{UUID: "a", CreatedAt: start, Cards: deck.Deck{card}, Owner: "alice"})
+
+		for _, test := range tests {
+			page, err := s.store.ListDecks(ctx, ListDecksOpts{Filter: test.filter})
+			if err != nil {
+				t.Errorf("Failed for %s store %s case: expected error to be %v, got %v", s.name, test.name, nil, err)
+			}
+			if uuids := deckUUIDs(page.Decks); !cmp.Equal(uuids, test.expected) {
+				t.Errorf("Failed for %s store %s case: expected decks to be %v, got %v", s.name, test.name, test.expected, uuids)
+			}
+			if page.NextCursor != "" {
+				t.Errorf("Failed for %s store %s case: expected no next cursor, got %q", s.name, test.name, page.NextCursor)
+			}
+		}
+
+		listed := []string{}
+		opts := ListDecksOpts{Limit: 3}
+		for pages := 0; pages < 3; pages++ {
+			page, err := s.store.ListDecks(ctx, opts)
+			if err != nil {
+				t.Fatalf("Failed for %s store pagination: expected error to be %v, got %v", s.name, nil, err)
+			}
+			listed = append(listed, deckUUIDs(page.Decks)...)
+			if page.NextCursor == "" {
+				break
+			}
+			opts.After = page.NextCursor
+		}
+		if expected := []string{"a", "b", "c", "d"}; !cmp.Equal(listed, expected) {
+			t.Errorf("Failed for %s store pagination: expected decks to be %v, got %v", s.name, expected, listed)
+		}
+
+		if _, err := s.store.ListDecks(ctx, ListDecksOpts{After: "not a cursor"}); err != ErrInvalidCursor {
+			t.Errorf("Failed for %s store invalid cursor: expected error to be %v, got %v", s.name, ErrInvalidCursor, err)
+		}
+	}
+}
+
+// withoutField returns doc without the field key.
+func withoutField(doc bson.D, key string) bson.D {
+	for i, e := range doc {
+		if e.Key == key {
+			return append(doc[:i], doc[i+1:]...)
+		}
+	}
+	return doc
+}
+
+func TestDeckCRUDOperatorListDecksWithoutCreatedAt(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("pages through decks stored without a creation time", func(mt *mtest.T) {
+		legacy := func(uuid string) bson.D {
+			return withoutField(mongoDeckDocument(t, DeckModel{UUID: uuid}, true), "created_at")
+		}
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "cardsdb.decks", mtest.FirstBatch, legacy("a"), legacy("b")),
+			mtest.CreateCursorResponse(0, "cardsdb.decks", mtest.FirstBatch, legacy("b"), legacy("c")),
+		)
+		dc := DeckCRUDOperator{Collection: mt.Coll}
+		first, err := dc.ListDecks(context.TODO(), ListDecksOpts{Limit: 1})
+		if err != nil || !cmp.Equal(deckUUIDs(first.Decks), []string{"a"}) || first.NextCursor == "" {
+			mt.Fatalf("Failed for first page: expected deck a, a next cursor and no error, got %v, %q and %v", deckUUIDs(first.Decks), first.NextCursor, err)
+		}
+		second, err := dc.ListDecks(context.TODO(), ListDecksOpts{Limit: 1, After: first.NextCursor})
+		if err != nil || !cmp.Equal(deckUUIDs(second.Decks), []string{"b"}) {
+			mt.Errorf("Failed for second page: expected deck b and no error, got %v and %v", deckUUIDs(second.Decks), err)
+		}
+
+		var filters []bson.Raw
+		for event := mt.GetStartedEvent(); event != nil; event = mt.GetStartedEvent() {
+			if event.CommandName == "find" {
+				filters = append(filters, event.Command.Lookup("filter").Document())
+			}
+		}
+		expected, _ := bson.Marshal(bson.D{{Key: "$or", Value: bson.A{
+			bson.D{{Key: "created_at", Value: bson.D{{Key: "$gt", Value: time.Time{}}}}},
+			bson.D{
+				{Key: "created_at", Value: bson.D{{Key: "$in", Value: bson.A{nil, time.Time{}}}}},
+				{Key: "uuid", Value: bson.D{{Key: "$gt", Value: "a"}}},
+			},
+		}}})
+		if len(filters) != 2 || !bytes.Equal(filters[1], expected) {
+			mt.Errorf("Failed for second page: expected the filter to be %v, got %v", bson.Raw(expected), filters)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/AbhilashJN/cards/deck"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrDuplicateUUID = errors.New("a deck with this uuid already exists")
+
+// MemoryDeckStore keeps decks in process memory. It is safe for concurrent use.
+type MemoryDeckStore struct {
+	mu    sync.Mutex
+	decks map[string]DeckModel
+}
+
+func NewMemoryDeckStore() *MemoryDeckStore {
+	return &MemoryDeckStore{decks: make(map[string]DeckModel)}
+}
+
+func (m *MemoryDeckStore) InsertDeck(ctx context.Context, deckItem DeckModel) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.decks[deckItem.UUID]; ok {
+		return ErrDuplicateUUID
+	}
+	m.decks[deckItem.UUID] = copyDeckModel(deckItem)
+	return nil
+}
+
+func (m *MemoryDeckStore) FindDeckByUUID(ctx context.Context, uuid string) (DeckModel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	deckItem, ok := m.decks[uuid]
+	if !ok {
+		return DeckModel{}, mongo.ErrNoDocuments
+	}
+	return copyDeckModel(deckItem), nil
+}
+
+func (m *MemoryDeckStore) UpdateDeckByUUID(ctx context.Context, uuid string, mutate DeckMutation) (DeckModel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored, ok := m.decks[uuid]
+	if !ok {
+		return DeckModel{}, mongo.ErrNoDocuments
+	}
+	deckItem := copyDeckModel(stored)
+	if err := mutate(&deckItem); err != nil {
+		return DeckModel{}, err
+	}
+	deckItem.Version = stored.Version + 1
+	deckItem.LastActivity = activityTime()
+	m.decks[uuid] = copyDeckModel(deckItem)
+	return deckItem, nil
+}
+
+func (m *MemoryDeckStore) DeleteDeckByUUID(ctx context.Context, uuid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.decks[uuid]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(m.decks, uuid)
+	return nil
+}
+
+// PurgeDecksIdleSince deletes every deck whose last activity was before
+// cutoff and returns how many were deleted.
+func (m *MemoryDeckStore) PurgeDecksIdleSince(ctx context.Context, cutoff time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	purged := 0
+	for uuid, deckItem := range m.decks {
+		if isIdleSince(deckItem, cutoff) {
+			delete(m.decks, uuid)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// ListDecks returns one page of the decks matching opts.Filter.
+func (m *MemoryDeckStore) ListDecks(ctx context.Context, opts ListDecksOpts) (DeckPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	decks := make([]DeckModel, 0, len(m.decks))
+	for _, deckItem := range m.decks {
+		decks = append(decks, deckItem)
+	}
+	page, err := pageOf(decks, opts)
+	for i := range page.Decks {
+		page.Decks[i] = copyDeckModel(page.Decks[i])
+	}
+	return page, err
+}
+
+// copyDeckModel returns a copy of d that shares no memory with it, so callers
+// can never modify a stored deck outside of the store's lock.
+func copyDeckModel(d DeckModel) DeckModel {
+	d.Cards = append(deck.Deck(nil), d.Cards...)
+	d.Original = append(deck.Deck(nil), d.Original...)
+	d.Drawn = append(deck.Deck(nil), d.Drawn...)
+	d.WantedCards = append([]string(nil), d.WantedCards...)
+	d.Tags = append([]string(nil), d.Tags...)
+	d.ExcludeRanks = append([]string(nil), d.ExcludeRanks...)
+	d.ExcludeSuits = append([]string(nil), d.ExcludeSuits...)
+	if d.Piles != nil {
+		piles := make(map[string]deck.Deck, len(d.Piles))
+		for name, pile := range d.Piles {
+			piles[name] = append(deck.Deck(nil), pile...)
+		}
+		d.Piles = piles
+	}
+	if d.Fair != nil {
+		fair := *d.Fair
+		fair.Unshuffled = append(deck.Deck(nil), fair.Unshuffled...)
+		d.Fair = &fair
+	}
+	return d
+}
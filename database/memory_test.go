@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/AbhilashJN/cards/deck"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMemoryDeckStoreNotFound(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	if _, err := store.FindDeckByUUID(ctx, "missing"); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for find missing deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+	if _, _, err := DrawCardsByUUID(ctx, store, "missing", &deck.DrawOpts{NumberOfCards: 1}, AnyVersion); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for draw from missing deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+}
+
+func TestMemoryDeckStoreDelete(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123"})
+	if err := store.DeleteDeckByUUID(ctx, "test-uuid-123"); err != nil {
+		t.Errorf("Failed for delete: expected error to be %v, got %v", nil, err)
+	}
+	if _, err := store.FindDeckByUUID(ctx, "test-uuid-123"); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for find deleted deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+	if err := store.DeleteDeckByUUID(ctx, "test-uuid-123"); err != mongo.ErrNoDocuments {
+		t.Errorf("Failed for delete missing deck: expected error to be %v, got %v", mongo.ErrNoDocuments, err)
+	}
+}
+
+func TestMemoryDeckStoreDuplicateInsert(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123"})
+	if err := store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123"}); err != ErrDuplicateUUID {
+		t.Errorf("Failed for duplicate insert: expected error to be %v, got %v", ErrDuplicateUUID, err)
+	}
+}
+
+func TestMemoryDeckStoreReturnsCopies(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards := deck.Deck{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Two, Suit: deck.Spades}}
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards})
+	cards[0].Value = deck.King
+
+	found, _ := store.FindDeckByUUID(ctx, "test-uuid-123")
+	found.Cards[1].Value = deck.Queen
+
+	found, _ = store.FindDeckByUUID(ctx, "test-uuid-123")
+	if found.Cards[0].Value != deck.Ace || found.Cards[1].Value != deck.Two {
+		t.Errorf("Failed for stored deck isolation: expected stored cards to be unchanged, got %v", found.Cards)
+	}
+}
+
+func TestDrawCardsByUUIDConcurrent(t *testing.T) {
+	const (
+		numWorkers     = 50
+		drawsPerWorker = 20
+		cardsPerDraw   = 3
+	)
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	var cards deck.Deck
+	for i := 0; i < numWorkers*drawsPerWorker*cardsPerDraw/52+1; i++ {
+		defaultDeck, _ := deck.New(&deck.NewDeckOpts{})
+		cards = append(cards, defaultDeck...)
+	}
+	expectedCounts := make(map[deck.Card]int)
+	for _, card := range cards {
+		expectedCounts[card]++
+	}
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards})
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		dealt = make(map[deck.Card]int)
+		total int
+	)
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < drawsPerWorker; i++ {
+				drawn, _, err := DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: cardsPerDraw}, AnyVersion)
+				if err != nil {
+					t.Errorf("Failed for concurrent draw: expected error to be %v, got %v", nil, err)
+					return
+				}
+				mu.Lock()
+				for _, card := range drawn {
+					dealt[card]++
+				}
+				total += len(drawn)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if total != numWorkers*drawsPerWorker*cardsPerDraw {
+		t.Errorf("Failed for concurrent draw: expected %d cards dealt, got %d", numWorkers*drawsPerWorker*cardsPerDraw, total)
+	}
+	remaining, _ := store.FindDeckByUUID(ctx, "test-uuid-123")
+	for _, card := range remaining.Cards {
+		dealt[card]++
+	}
+	for card, count := range dealt {
+		if count != expectedCounts[card] {
+			t.Errorf("Failed for concurrent draw: expected card %v to be seen %d times, got %d", card, expectedCounts[card], count)
+		}
+	}
+	if remaining.Version != numWorkers*drawsPerWorker {
+		t.Errorf("Failed for concurrent draw: expected version %d, got %d", numWorkers*drawsPerWorker, remaining.Version)
+	}
+}
+
+func TestDrawCardsByUUIDVersionMismatch(t *testing.T) {
+	store := NewMemoryDeckStore()
+	ctx := context.TODO()
+	cards, _ := deck.New(&deck.NewDeckOpts{})
+	store.InsertDeck(ctx, DeckModel{UUID: "test-uuid-123", Cards: cards, Version: 1})
+
+	_, updated, err := DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 2}, 1)
+	if err != nil {
+		t.Errorf("Failed for matching version: expected error to be %v, got %v", nil, err)
+	}
+	if updated.Version != 2 {
+		t.Errorf("Failed for matching version: expected version to be %d, got %d", 2, updated.Version)
+	}
+	if _, _, err = DrawCardsByUUID(ctx, store, "test-uuid-123", &deck.DrawOpts{NumberOfCards: 2}, 1); err != ErrVersionMismatch {
+		t.Errorf("Failed for stale version: expected error to be %v, got %v", ErrVersionMismatch, err)
+	}
+	found, _ := store.FindDeckByUUID(ctx, "test-uuid-123")
+	if len(found.Cards) != 50 || found.Version != 2 {
+		t.Errorf("Failed for stale version: expected deck to be unchanged, got %d cards at version %d", len(found.Cards), found.Version)
+	}
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/AbhilashJN/cards/deck"
+)
+
+var ErrPileNotFound = errors.New("pile does not exist")
+
+// AddToPileByUUID moves cards from the deck onto the top of the named pile,
+// creating the pile if needed. The cards are either the ones listed in
+// cardCodes or, if none are listed, the top n cards of the deck.
+func AddToPileByUUID(ctx context.Context, dc DeckCRUDer, uuid string, pile string, cardCodes []string, n int, ifVersion int64) (deck.Deck, DeckModel, error) {
+	var movedCards deck.Deck
+	updated, err := dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		var (
+			moved, remaining deck.Deck
+			err              error
+		)
+		if len(cardCodes) > 0 {
+			moved, remaining, err = deck.DrawSpecificCards(d.Cards, cardCodes)
+		} else {
+			moved, remaining, err = deck.DrawCards(d.Cards, n)
+		}
+		if err != nil {
+			return err
+		}
+		if d.Piles == nil {
+			d.Piles = make(map[string]deck.Deck)
+		}
+		d.Piles[pile] = append(append(deck.Deck{}, moved...), d.Piles[pile]...)
+		movedCards, d.Cards = moved, remaining
+		return nil
+	})
+	return movedCards, updated, err
+}
+
+// DrawFromPileByUUID removes the cards described by opts from the named pile
+// and returns them along with the updated deck.
+func DrawFromPileByUUID(ctx context.Context, dc DeckCRUDer, uuid string, pile string, opts *deck.DrawOpts, ifVersion int64) (deck.Deck, DeckModel, error) {
+	var drawnCards deck.Deck
+	updated, err := dc.UpdateDeckByUUID(ctx, uuid, func(d *DeckModel) error {
+		if err := checkWritable(d, ifVersion); err != nil {
+			return err
+		}
+		pileCards, ok := d.Piles[pile]
+		if !ok {
+			return ErrPileNotFound
+		}
+		src, err := drawSource(d, opts)
+		if err != nil {
+			return err
+		}
+		drawn, remaining, err := deck.Draw(pileCards, opts, src)
+		if err != nil {
+			return err
+		}
+		drawnCards, d.Piles[pile] = drawn, remaining
+		d.Drawn = append(d.Drawn, drawn...)
+		return nil
+	})
+	return drawnCards, updated, err
+}
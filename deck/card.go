@@ -16,6 +16,22 @@ const (
 	Diamonds
 	Clubs
 	Hearts
+	// Black and Red are only used as the colour of a Joker.
+	Black
+	Red
+	// Trumps holds the trumps and the Fool of a tarot deck.
+	Trumps
+	// Cups, Coins, Swords and Batons are the suits of Italian and Spanish
+	// decks.
+	Cups
+	Coins
+	Swords
+	Batons
+	// Acorns, Leaves and Bells are the suits of German decks, along with
+	// Hearts.
+	Acorns
+	Leaves
+	Bells
 )
 
 const (
@@ -32,6 +48,36 @@ const (
 	Jack                       // Jack
 	Queen                      // Queen
 	King                       // King
+	Joker                      // Joker
+	// Knight ranks between Jack and Queen in tarot decks, and between Jack
+	// and King in Italian and Spanish decks.
+	Knight // Knight
+	// Unter and Ober are the face cards of German decks, along with King.
+	Unter // Unter
+	Ober  // Ober
+	// Fool and Trump1 to Trump21 are only used in the Trumps suit.
+	Fool    // Fool
+	Trump1  // 1
+	Trump2  // 2
+	Trump3  // 3
+	Trump4  // 4
+	Trump5  // 5
+	Trump6  // 6
+	Trump7  // 7
+	Trump8  // 8
+	Trump9  // 9
+	Trump10 // 10
+	Trump11 // 11
+	Trump12 // 12
+	Trump13 // 13
+	Trump14 // 14
+	Trump15 // 15
+	Trump16 // 16
+	Trump17 // 17
+	Trump18 // 18
+	Trump19 // 19
+	Trump20 // 20
+	Trump21 // 21
 )
 
 type Card struct {
@@ -39,6 +85,66 @@ type Card struct {
 	Suit  CardSuit  `json:"suit" bson:"suit"`
 }
 
+var (
+	BlackJoker = Card{Value: Joker, Suit: Black}
+	RedJoker   = Card{Value: Joker, Suit: Red}
+)
+
+const (
+	blackJokerCode = "X1"
+	redJokerCode   = "X2"
+)
+
+// valueCodes and suitCodes hold the canonical code of every value and suit.
+// A card's code is its value code followed by its suit code. Every suit code
+// is a single character, and so is every value code outside of the Trumps
+// suit.
+var valueCodes = map[CardValue]string{
+	Ace: "A", Two: "2", Three: "3", Four: "4", Five: "5", Six: "6", Seven: "7",
+	Eight: "8", Nine: "9", Ten: "0", Jack: "J", Queen: "Q", King: "K",
+	Knight: "N", Unter: "U", Ober: "O",
+}
+
+var suitCodes = map[CardSuit]string{
+	Spades: "S", Diamonds: "D", Clubs: "C", Hearts: "H", Trumps: "T",
+	Cups: "P", Coins: "O", Swords: "W", Batons: "B",
+	Acorns: "A", Leaves: "L", Bells: "E",
+}
+
+// trumpCodes are the value codes of the Trumps suit: the number of the trump,
+// or "F" for the Fool. The 21st trump is "21T".
+var trumpCodes = map[CardValue]string{Fool: "F"}
+
+// valueAliases are accepted as value codes in addition to the canonical ones.
+// "1" is what Ten used to be encoded as, so older clients keep working.
+var valueAliases = map[string]CardValue{
+	"10": Ten, "T": Ten, "1": Ten,
+}
+
+var (
+	valuesByCode      = make(map[string]CardValue)
+	trumpValuesByCode = make(map[string]CardValue)
+	suitsByCode       = make(map[string]CardSuit)
+)
+
+func init() {
+	for value, code := range valueCodes {
+		valuesByCode[code] = value
+	}
+	for code, value := range valueAliases {
+		valuesByCode[code] = value
+	}
+	for suit, code := range suitCodes {
+		suitsByCode[code] = suit
+	}
+	for i := 1; i <= 21; i++ {
+		trumpCodes[Trump1+CardValue(i-1)] = strconv.Itoa(i)
+	}
+	for value, code := range trumpCodes {
+		trumpValuesByCode[code] = value
+	}
+}
+
 type CardJSON struct {
 	Value string `json:"value"`
 	Suit  string `json:"suit"`
@@ -53,56 +159,68 @@ func (e ErrInvalidCardCode) Error() string {
 	return fmt.Sprintf("Card code %s is invalid", e.CardCode)
 }
 
-func (c Card) ToCardJSON() CardJSON {
-	valueString := strings.ToUpper(c.Value.String())
-	suitString := strings.ToUpper(c.Suit.String())
-	code := []byte{valueString[0], suitString[0]}
+func (c Card) IsJoker() bool {
+	return c.Value == Joker
+}
 
+// Code returns the canonical code of the card, e.g. "AS", "0H", "X1" or
+// "21T". DecodeValueAndSuit always decodes it back to the same card.
+func (c Card) Code() string {
+	switch c {
+	case BlackJoker:
+		return blackJokerCode
+	case RedJoker:
+		return redJokerCode
+	}
+	if c.Suit == Trumps {
+		return trumpCodes[c.Value] + suitCodes[c.Suit]
+	}
+	return valueCodes[c.Value] + suitCodes[c.Suit]
+}
+
+// Code returns the canonical code of the value, e.g. "A" or "0". Trumps and
+// the Fool have no value code of their own.
+func (v CardValue) Code() string {
+	return valueCodes[v]
+}
+
+// Code returns the canonical code of the suit, e.g. "S".
+func (s CardSuit) Code() string {
+	return suitCodes[s]
+}
+
+func (c Card) ToCardJSON() CardJSON {
 	return CardJSON{
-		Value: valueString,
-		Suit:  suitString,
-		Code:  string(code),
+		Value: strings.ToUpper(c.Value.String()),
+		Suit:  strings.ToUpper(c.Suit.String()),
+		Code:  c.Code(),
 	}
 }
 
+// DecodeValueAndSuit parses a card code. Besides the canonical codes returned
+// by Card.Code it accepts lower case codes and "10", "T" or "1" for Ten. Codes
+// of cards that are in no deck family, such as "US", are invalid.
 func DecodeValueAndSuit(code string) (CardValue, CardSuit, error) {
-	var (
-		value    CardValue
-		suit     CardSuit
-		parseErr error
-	)
-	valueCode := string(code[0])
-	suitCode := string(code[1])
-
-	valueInt, err := strconv.Atoi(valueCode)
-	if err != nil {
-		switch valueCode {
-		case "A":
-			value = Ace
-		case "J":
-			value = Jack
-		case "Q":
-			value = Queen
-		case "K":
-			value = King
-		default:
-			parseErr = ErrInvalidCardCode{CardCode: code}
-		}
-	} else {
-		value = CardValue(valueInt)
+	normalized := strings.ToUpper(strings.TrimSpace(code))
+	switch normalized {
+	case blackJokerCode:
+		return BlackJoker.Value, BlackJoker.Suit, nil
+	case redJokerCode:
+		return RedJoker.Value, RedJoker.Suit, nil
+	}
+	if len(normalized) < 2 {
+		return 0, 0, ErrInvalidCardCode{CardCode: code}
 	}
 
-	switch suitCode {
-	case "S":
-		suit = Spades
-	case "D":
-		suit = Diamonds
-	case "C":
-		suit = Clubs
-	case "H":
-		suit = Hearts
-	default:
-		parseErr = ErrInvalidCardCode{CardCode: code}
+	splitAt := len(normalized) - 1
+	suit, suitOk := suitsByCode[normalized[splitAt:]]
+	valueCodes := valuesByCode
+	if suit == Trumps {
+		valueCodes = trumpValuesByCode
+	}
+	value, valueOk := valueCodes[normalized[:splitAt]]
+	if !valueOk || !suitOk || !familyCards[Card{Value: value, Suit: suit}] {
+		return 0, 0, ErrInvalidCardCode{CardCode: code}
 	}
-	return value, suit, parseErr
+	return value, suit, nil
 }
@@ -2,6 +2,7 @@ package deck
 
 import (
 	"testing"
+	"testing/quick"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -35,6 +36,20 @@ func TestTableDecodeValueAndSuit(t *testing.T) {
 		{"8H", Eight, Hearts, nil},
 		{"QD", Queen, Diamonds, nil},
 		{"0M", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: "0M"}},
+		{"X1", Joker, Black, nil},
+		{"X2", Joker, Red, nil},
+		{"X3", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: "X3"}},
+		{"AR", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: "AR"}},
+		{"0S", Ten, Spades, nil},
+		{"10S", Ten, Spades, nil},
+		{"TS", Ten, Spades, nil},
+		{"1S", Ten, Spades, nil},
+		{"td", Ten, Diamonds, nil},
+		{"kh", King, Hearts, nil},
+		{"x2", Joker, Red, nil},
+		{"11S", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: "11S"}},
+		{"A", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: "A"}},
+		{"", CardValue(0), CardSuit(0), ErrInvalidCardCode{CardCode: ""}},
 	}
 
 	for _, test := range tests {
@@ -58,6 +73,7 @@ func TestCardValueStringer(t *testing.T) {
 		{Five, "5"},
 		{Eight, "8"},
 		{King, "King"},
+		{Joker, "Joker"},
 	}
 
 	for _, test := range tests {
@@ -73,6 +89,8 @@ func TestCardSuitStringer(t *testing.T) {
 		{Diamonds, "Diamonds"},
 		{Clubs, "Clubs"},
 		{Hearts, "Hearts"},
+		{Black, "Black"},
+		{Red, "Red"},
 	}
 
 	for _, test := range tests {
@@ -87,6 +105,9 @@ func TestTableToCardJSON(t *testing.T) {
 		{Card{Value: Six, Suit: Clubs}, CardJSON{Value: "6", Suit: "CLUBS", Code: "6C"}},
 		{Card{Value: Jack, Suit: Hearts}, CardJSON{Value: "JACK", Suit: "HEARTS", Code: "JH"}},
 		{Card{Value: Ace, Suit: Spades}, CardJSON{Value: "ACE", Suit: "SPADES", Code: "AS"}},
+		{BlackJoker, CardJSON{Value: "JOKER", Suit: "BLACK", Code: "X1"}},
+		{RedJoker, CardJSON{Value: "JOKER", Suit: "RED", Code: "X2"}},
+		{Card{Value: Ten, Suit: Diamonds}, CardJSON{Value: "10", Suit: "DIAMONDS", Code: "0D"}},
 	}
 
 	for _, test := range tests {
@@ -96,3 +117,56 @@ func TestTableToCardJSON(t *testing.T) {
 		}
 	}
 }
+
+func allCards() Deck {
+	cards := Deck{BlackJoker, RedJoker}
+	for suit := Spades; suit <= Hearts; suit++ {
+		for value := Ace; value <= King; value++ {
+			cards = append(cards, Card{Value: value, Suit: suit})
+		}
+	}
+	return cards
+}
+
+func TestCardCodeRoundTrip(t *testing.T) {
+	seen := make(map[string]Card)
+	for _, card := range allCards() {
+		code := card.Code()
+		if other, ok := seen[code]; ok {
+			t.Errorf("Failed for card %v: code %s is also used by %v", card, code, other)
+		}
+		seen[code] = card
+		if code != card.ToCardJSON().Code {
+			t.Errorf("Failed for card %v: expected card json code to be %s, got %s", card, code, card.ToCardJSON().Code)
+		}
+
+		value, suit, err := DecodeValueAndSuit(code)
+		if err != nil || (Card{Value: value, Suit: suit}) != card {
+			t.Errorf("Failed for card %v: code %s decoded to %v %v with error %v", card, code, value, suit, err)
+		}
+	}
+}
+
+func TestDecodedCardCodeRoundTrip(t *testing.T) {
+	// Any code that decodes, including aliases, must re-encode to a canonical
+	// code that decodes to the same card.
+	property := func(code string) bool {
+		value, suit, err := DecodeValueAndSuit(code)
+		if err != nil {
+			return true
+		}
+		card := Card{Value: value, Suit: suit}
+		value, suit, err = DecodeValueAndSuit(card.Code())
+		return err == nil && (Card{Value: value, Suit: suit}) == card
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+	for code := range valuesByCode {
+		for _, suitCode := range []string{"S", "D", "C", "H"} {
+			if !property(code + suitCode) {
+				t.Errorf("Failed for code %s: does not round trip", code+suitCode)
+			}
+		}
+	}
+}
@@ -21,11 +21,37 @@ func _() {
 	_ = x[Jack-11]
 	_ = x[Queen-12]
 	_ = x[King-13]
+	_ = x[Joker-14]
+	_ = x[Knight-15]
+	_ = x[Unter-16]
+	_ = x[Ober-17]
+	_ = x[Fool-18]
+	_ = x[Trump1-19]
+	_ = x[Trump2-20]
+	_ = x[Trump3-21]
+	_ = x[Trump4-22]
+	_ = x[Trump5-23]
+	_ = x[Trump6-24]
+	_ = x[Trump7-25]
+	_ = x[Trump8-26]
+	_ = x[Trump9-27]
+	_ = x[Trump10-28]
+	_ = x[Trump11-29]
+	_ = x[Trump12-30]
+	_ = x[Trump13-31]
+	_ = x[Trump14-32]
+	_ = x[Trump15-33]
+	_ = x[Trump16-34]
+	_ = x[Trump17-35]
+	_ = x[Trump18-36]
+	_ = x[Trump19-37]
+	_ = x[Trump20-38]
+	_ = x[Trump21-39]
 }
 
-const _CardValue_name = "Ace2345678910JackQueenKing"
+const _CardValue_name = "Ace2345678910JackQueenKingJokerKnightUnterOberFool123456789101112131415161718192021"
 
-var _CardValue_index = [...]uint8{0, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 17, 22, 26}
+var _CardValue_index = [...]uint8{0, 3, 4, 5, 6, 7, 8, 9, 10, 11, 13, 17, 22, 26, 31, 37, 42, 46, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59, 61, 63, 65, 67, 69, 71, 73, 75, 77, 79, 81, 83}
 
 func (i CardValue) String() string {
 	i -= 1
@@ -42,11 +68,21 @@ func _() {
 	_ = x[Diamonds-1]
 	_ = x[Clubs-2]
 	_ = x[Hearts-3]
+	_ = x[Black-4]
+	_ = x[Red-5]
+	_ = x[Trumps-6]
+	_ = x[Cups-7]
+	_ = x[Coins-8]
+	_ = x[Swords-9]
+	_ = x[Batons-10]
+	_ = x[Acorns-11]
+	_ = x[Leaves-12]
+	_ = x[Bells-13]
 }
 
-const _CardSuit_name = "SpadesDiamondsClubsHearts"
+const _CardSuit_name = "SpadesDiamondsClubsHeartsBlackRedTrumpsCupsCoinsSwordsBatonsAcornsLeavesBells"
 
-var _CardSuit_index = [...]uint8{0, 6, 14, 19, 25}
+var _CardSuit_index = [...]uint8{0, 6, 14, 19, 25, 30, 33, 39, 43, 48, 54, 60, 66, 72, 77}
 
 func (i CardSuit) String() string {
 	if i < 0 || i >= CardSuit(len(_CardSuit_index)-1) {
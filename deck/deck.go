@@ -1,9 +1,12 @@
 package deck
 
 import (
-	"math/rand"
+	"fmt"
 )
 
+// MaxDecks is the largest number of decks that can be combined into a shoe.
+const MaxDecks = 10
+
 type Deck []Card
 
 type DeckJSON []CardJSON
@@ -12,6 +15,60 @@ type NewDeckOpts struct {
 	Shuffle         bool
 	CustomDeck      bool
 	CustomDeckCards []string
+	// Family selects the kind of deck. The zero value means FamilyFrench.
+	Family Family
+	// Preset selects a well known composition instead of the full deck.
+	// The zero value means PresetStandard.
+	Preset Preset
+	// ExcludeValues and ExcludeSuits remove every card with one of these
+	// values or suits from the preset or custom deck.
+	ExcludeValues []CardValue
+	ExcludeSuits  []CardSuit
+	// Decks is the number of copies of the deck combined into a single shoe.
+	// Zero means a single deck.
+	Decks int
+	// IncludeJokers adds a black and a red joker to every deck in the shoe.
+	IncludeJokers bool
+	// Seed makes the shuffle reproducible: the same seed and cards always
+	// give the same order. If nil, an unpredictable seed is used.
+	Seed *int64
+	// RNG selects the random number generator used to shuffle. The zero
+	// value means RNGMath.
+	RNG RNG
+	// ShuffleMethod and Passes select how the deck is shuffled. The zero
+	// values mean a single ShuffleRandom pass.
+	ShuffleMethod ShuffleMethod
+	Passes        int
+	// ServerSeed and ClientSeed are the seeds of an RNGFair shuffle. They
+	// are ignored by the other RNGs.
+	ServerSeed string
+	ClientSeed string
+}
+
+// DrawMode selects where in the deck cards are drawn from.
+type DrawMode string
+
+const (
+	DrawTop    DrawMode = "top"
+	DrawBottom DrawMode = "bottom"
+	DrawRandom DrawMode = "random"
+)
+
+// DrawOpts describes the cards to draw: the cards with the codes listed in
+// Cards, if any, or otherwise NumberOfCards cards taken as Mode says. The
+// zero Mode means DrawTop.
+type DrawOpts struct {
+	Mode          DrawMode
+	NumberOfCards int
+	Cards         []string
+}
+
+type ErrUnknownDrawMode struct {
+	Mode DrawMode
+}
+
+func (e ErrUnknownDrawMode) Error() string {
+	return fmt.Sprintf("Draw mode %s is unknown", e.Mode)
 }
 
 type ErrDrawCardsSizeExceeded struct {
@@ -21,6 +78,22 @@ func (e ErrDrawCardsSizeExceeded) Error() string {
 	return "Requested number of cards is greater than the cards remaining in the deck"
 }
 
+type ErrInvalidNumberOfDecks struct {
+	Decks int
+}
+
+func (e ErrInvalidNumberOfDecks) Error() string {
+	return fmt.Sprintf("Number of decks must be between 1 and %d, got %d", MaxDecks, e.Decks)
+}
+
+type ErrCardNotInDeck struct {
+	CardCode string
+}
+
+func (e ErrCardNotInDeck) Error() string {
+	return fmt.Sprintf("Card %s is not in the deck", e.CardCode)
+}
+
 func (d Deck) ToDeckJSON() DeckJSON {
 	deckJSON := make(DeckJSON, len(d))
 	for i, card := range d {
@@ -29,8 +102,8 @@ func (d Deck) ToDeckJSON() DeckJSON {
 	return deckJSON
 }
 
-func (d Deck) Shuffle() {
-	rand.Shuffle(len(d), func(i, j int) {
+func (d Deck) Shuffle(src Source) {
+	src.Shuffle(len(d), func(i, j int) {
 		d[i], d[j] = d[j], d[i]
 	})
 }
@@ -44,6 +117,104 @@ func DrawCards(d Deck, n int) (Deck, Deck, error) {
 	return draw, remaining, nil
 }
 
+// DrawCardsFromBottom removes the bottom n cards of d. The bottom card is the
+// first card drawn.
+func DrawCardsFromBottom(d Deck, n int) (Deck, Deck, error) {
+	size := len(d)
+	if n > size {
+		return nil, nil, ErrDrawCardsSizeExceeded{}
+	}
+	draw := make(Deck, n)
+	for i := range draw {
+		draw[i] = d[size-1-i]
+	}
+	return draw, d[:size-n], nil
+}
+
+// DrawRandomCards removes n cards from uniformly random positions of d.
+func DrawRandomCards(d Deck, n int, src Source) (Deck, Deck, error) {
+	if n > len(d) {
+		return nil, nil, ErrDrawCardsSizeExceeded{}
+	}
+	remaining := append(Deck{}, d...)
+	draw := make(Deck, n)
+	for i := range draw {
+		idx := src.Intn(len(remaining))
+		draw[i] = remaining[idx]
+		remaining = append(remaining[:idx], remaining[idx+1:]...)
+	}
+	return draw, remaining, nil
+}
+
+// Draw removes the cards described by opts from d. src is only used by
+// DrawRandom and may be nil otherwise.
+func Draw(d Deck, opts *DrawOpts, src Source) (Deck, Deck, error) {
+	if len(opts.Cards) > 0 {
+		return DrawSpecificCards(d, opts.Cards)
+	}
+	switch opts.Mode {
+	case "", DrawTop:
+		return DrawCards(d, opts.NumberOfCards)
+	case DrawBottom:
+		return DrawCardsFromBottom(d, opts.NumberOfCards)
+	case DrawRandom:
+		return DrawRandomCards(d, opts.NumberOfCards, src)
+	default:
+		return nil, nil, ErrUnknownDrawMode{Mode: opts.Mode}
+	}
+}
+
+// Peek returns the top n cards of d, or with DrawBottom the bottom n cards,
+// bottom card first, without removing them.
+func Peek(d Deck, mode DrawMode, n int) (Deck, error) {
+	var (
+		peeked Deck
+		err    error
+	)
+	switch mode {
+	case "", DrawTop:
+		peeked, _, err = DrawCards(d, n)
+	case DrawBottom:
+		peeked, _, err = DrawCardsFromBottom(d, n)
+	default:
+		return nil, ErrUnknownDrawMode{Mode: mode}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append(Deck{}, peeked...), nil
+}
+
+// DrawSpecificCards removes the cards with the given codes from d, in the
+// order they are listed. A code listed twice removes two copies of the card.
+func DrawSpecificCards(d Deck, cardCodes []string) (Deck, Deck, error) {
+	remaining := append(Deck{}, d...)
+	draw := make(Deck, 0, len(cardCodes))
+	for _, cardCode := range cardCodes {
+		value, suit, err := DecodeValueAndSuit(cardCode)
+		if err != nil {
+			return nil, nil, err
+		}
+		card := Card{Value: value, Suit: suit}
+		idx := remaining.indexOf(card)
+		if idx < 0 {
+			return nil, nil, ErrCardNotInDeck{CardCode: card.Code()}
+		}
+		draw = append(draw, card)
+		remaining = append(remaining[:idx], remaining[idx+1:]...)
+	}
+	return draw, remaining, nil
+}
+
+func (d Deck) indexOf(card Card) int {
+	for i, c := range d {
+		if c == card {
+			return i
+		}
+	}
+	return -1
+}
+
 func defaultDeckGenerator() Deck {
 	newDeck := make(Deck, 52)
 	idx := 0
@@ -69,21 +240,93 @@ func customDeckGenerator(cardCodesList []string) (Deck, error) {
 	return newDeck, nil
 }
 
+// shoeGenerator combines n copies of d into a single deck.
+func shoeGenerator(d Deck, n int) Deck {
+	shoe := make(Deck, 0, len(d)*n)
+	for i := 0; i < n; i++ {
+		shoe = append(shoe, d...)
+	}
+	return shoe
+}
+
 func New(opts *NewDeckOpts) (Deck, error) {
 	var deck Deck
 	var err error
-	if opts.CustomDeck {
+	numDecks := opts.Decks
+	if numDecks == 0 {
+		numDecks = 1
+	}
+	if numDecks < 0 || numDecks > MaxDecks {
+		return Deck{}, ErrInvalidNumberOfDecks{Decks: opts.Decks}
+	}
+	// The shuffle method is checked even if the deck is not shuffled, so a
+	// bad one is not silently ignored.
+	if err := checkShuffle(opts.ShuffleMethod, opts.Passes); err != nil {
+		return Deck{}, err
+	}
+	isFrench := opts.Family == "" || opts.Family == FamilyFrench
+	switch {
+	case opts.CustomDeck && opts.Preset != "":
+		return Deck{}, ErrPresetWithCustomDeck
+	case opts.CustomDeck && !isFrench:
+		return Deck{}, ErrFamilyWithCustomDeck
+	case opts.Preset != "" && !isFrench:
+		return Deck{}, ErrPresetWithFamily
+	case opts.CustomDeck:
 		deck, err = customDeckGenerator(opts.CustomDeckCards)
-		if err != nil {
-			return deck, err
-		}
-	} else {
+	case opts.Preset != "":
+		deck, err = presetDeckGenerator(opts.Preset)
+	case !isFrench:
+		deck, err = familyDeckGenerator(opts.Family)
+	default:
 		deck = defaultDeckGenerator()
 	}
+	if err != nil {
+		return deck, err
+	}
+	if len(opts.ExcludeValues) > 0 || len(opts.ExcludeSuits) > 0 {
+		deck = excludeCards(deck, opts.ExcludeValues, opts.ExcludeSuits)
+		if len(deck) == 0 {
+			return Deck{}, ErrEmptyDeck
+		}
+	}
+	if opts.IncludeJokers {
+		deck = append(deck, BlackJoker, RedJoker)
+	}
+	if numDecks > 1 {
+		deck = shoeGenerator(deck, numDecks)
+	}
 
+	src, err := newDeckSource(opts)
+	if err != nil {
+		return Deck{}, err
+	}
 	if opts.Shuffle {
-		deck.Shuffle()
+		if err = deck.ShuffleBy(opts.ShuffleMethod, opts.Passes, src); err != nil {
+			return Deck{}, err
+		}
 	}
 
 	return deck, nil
 }
+
+func newDeckSource(opts *NewDeckOpts) (Source, error) {
+	if opts.RNG != RNGFair {
+		return NewSource(opts.RNG, opts.Seed)
+	}
+	if opts.Seed != nil {
+		return nil, ErrSeedWithFairRNG
+	}
+	if opts.ShuffleMethod != "" && opts.ShuffleMethod != ShuffleRandom {
+		return nil, ErrMethodWithFairRNG
+	}
+	if opts.ClientSeed == "" {
+		// The deck can be created first and shuffled once the client seed
+		// is known.
+		if opts.Shuffle {
+			return nil, ErrClientSeedMissing
+		}
+		return nil, nil
+	}
+	return NewFairSource(opts.ServerSeed, opts.ClientSeed), nil
+}
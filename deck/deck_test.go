@@ -69,6 +69,10 @@ func TestCustomDeckGenerator(t *testing.T) {
 			},
 			nil,
 		},
+		{[]string{"X1", "AS", "X2"},
+			Deck{BlackJoker, {Value: Ace, Suit: Spades}, RedJoker},
+			nil,
+		},
 		{[]string{"8C", "5H", "XY", "AD", "7S", "3C"},
 			Deck{},
 			ErrInvalidCardCode{CardCode: "XY"},
@@ -107,14 +111,12 @@ func TestShuffle(t *testing.T) {
 			},
 		},
 	}
-	rand.Seed(123)
 	for _, test := range tests {
-		test.input.Shuffle()
+		test.input.Shuffle(rand.New(rand.NewSource(123)))
 		if !cmp.Equal(test.input, test.expectedOutput) {
 			t.Errorf("Failed: expected %v, got %v", test.expectedOutput, test.input)
 		}
 	}
-	rand.Seed(1)
 }
 
 func TestDrawCards(t *testing.T) {
@@ -249,7 +251,7 @@ func TestNewDeck(t *testing.T) {
 			nil,
 		},
 		{
-			NewDeckOpts{Shuffle: true, CustomDeck: false, CustomDeckCards: []string{}},
+			NewDeckOpts{Shuffle: true, CustomDeck: false, CustomDeckCards: []string{}, Seed: seed(123)},
 			getShuffledDefaultDeck(),
 			nil,
 		},
@@ -265,13 +267,13 @@ func TestNewDeck(t *testing.T) {
 			nil,
 		},
 		{
-			NewDeckOpts{Shuffle: true, CustomDeck: true, CustomDeckCards: []string{"AS", "QS", "2H", "7D", "4C"}},
+			NewDeckOpts{Shuffle: true, CustomDeck: true, CustomDeckCards: []string{"AS", "QS", "2H", "7D", "4C"}, Seed: seed(42)},
 			Deck{
-				{Value: Queen, Suit: Spades},
-				{Value: Ace, Suit: Spades},
+				{Value: Two, Suit: Hearts},
 				{Value: Seven, Suit: Diamonds},
 				{Value: Four, Suit: Clubs},
-				{Value: Two, Suit: Hearts},
+				{Value: Ace, Suit: Spades},
+				{Value: Queen, Suit: Spades},
 			},
 			nil,
 		},
@@ -286,7 +288,6 @@ func TestNewDeck(t *testing.T) {
 			nil,
 		},
 	}
-	rand.Seed(123)
 	for _, test := range tests {
 		output, err := New(&test.inputOpts)
 		if !cmp.Equal(output, test.expectedOutput) {
@@ -296,5 +297,227 @@ func TestNewDeck(t *testing.T) {
 			t.Errorf("Failed for input %v: expected error %v, got %v", test.inputOpts, test.expectedErr, err)
 		}
 	}
-	rand.Seed(1)
+}
+
+func TestNewDeckShoe(t *testing.T) {
+	shoe, err := New(&NewDeckOpts{Decks: 6})
+	if err != nil {
+		t.Errorf("Failed for six deck shoe: expected error %v, got %v", nil, err)
+	}
+	if len(shoe) != 6*52 {
+		t.Errorf("Failed for six deck shoe: expected %d cards, got %d", 6*52, len(shoe))
+	}
+	counts := make(map[Card]int)
+	for _, card := range shoe {
+		counts[card]++
+	}
+	for _, card := range getDefaultDeck() {
+		if counts[card] != 6 {
+			t.Errorf("Failed for six deck shoe: expected %d copies of %v, got %d", 6, card, counts[card])
+		}
+	}
+
+	shoe, _ = New(&NewDeckOpts{Decks: 2, CustomDeck: true, CustomDeckCards: []string{"AS", "KH"}})
+	expected := Deck{{Value: Ace, Suit: Spades}, {Value: King, Suit: Hearts}, {Value: Ace, Suit: Spades}, {Value: King, Suit: Hearts}}
+	if !cmp.Equal(shoe, expected) {
+		t.Errorf("Failed for custom two deck shoe: expected %v, got %v", expected, shoe)
+	}
+
+	for _, decks := range []int{-1, MaxDecks + 1} {
+		_, err = New(&NewDeckOpts{Decks: decks})
+		if !cmp.Equal(err, ErrInvalidNumberOfDecks{Decks: decks}) {
+			t.Errorf("Failed for %d decks: expected error %v, got %v", decks, ErrInvalidNumberOfDecks{Decks: decks}, err)
+		}
+	}
+}
+
+func TestNewDeckJokers(t *testing.T) {
+	d, _ := New(&NewDeckOpts{IncludeJokers: true})
+	expected := append(getDefaultDeck(), BlackJoker, RedJoker)
+	if !cmp.Equal(d, expected) {
+		t.Errorf("Failed for deck with jokers: expected %v, got %v", expected, d)
+	}
+
+	d, _ = New(&NewDeckOpts{IncludeJokers: true, Decks: 2, CustomDeck: true, CustomDeckCards: []string{"AS"}})
+	expected = Deck{{Value: Ace, Suit: Spades}, BlackJoker, RedJoker, {Value: Ace, Suit: Spades}, BlackJoker, RedJoker}
+	if !cmp.Equal(d, expected) {
+		t.Errorf("Failed for custom shoe with jokers: expected %v, got %v", expected, d)
+	}
+}
+
+func TestDrawSpecificCards(t *testing.T) {
+	d := Deck{
+		{Value: Queen, Suit: Spades},
+		{Value: Ten, Suit: Clubs},
+		{Value: Five, Suit: Hearts},
+		{Value: Queen, Suit: Spades},
+	}
+	drawn, remaining, err := DrawSpecificCards(d, []string{"5H", "QS", "10C"})
+	expectedDrawn := Deck{{Value: Five, Suit: Hearts}, {Value: Queen, Suit: Spades}, {Value: Ten, Suit: Clubs}}
+	if !cmp.Equal(drawn, expectedDrawn) {
+		t.Errorf("Failed for specific draw: expected drawn deck to be %v, got %v", expectedDrawn, drawn)
+	}
+	if !cmp.Equal(remaining, Deck{{Value: Queen, Suit: Spades}}) {
+		t.Errorf("Failed for specific draw: expected remaining deck to be %v, got %v", Deck{{Value: Queen, Suit: Spades}}, remaining)
+	}
+	if err != nil {
+		t.Errorf("Failed for specific draw: expected error to be %v, got %v", nil, err)
+	}
+	if d[0] != (Card{Value: Queen, Suit: Spades}) || len(d) != 4 {
+		t.Errorf("Failed for specific draw: expected input deck to be unchanged, got %v", d)
+	}
+
+	_, _, err = DrawSpecificCards(d, []string{"5H", "5H"})
+	if !cmp.Equal(err, ErrCardNotInDeck{CardCode: "5H"}) {
+		t.Errorf("Failed for missing card: expected error to be %v, got %v", ErrCardNotInDeck{CardCode: "5H"}, err)
+	}
+	_, _, err = DrawSpecificCards(d, []string{"ZZ"})
+	if !cmp.Equal(err, ErrInvalidCardCode{CardCode: "ZZ"}) {
+		t.Errorf("Failed for invalid card: expected error to be %v, got %v", ErrInvalidCardCode{CardCode: "ZZ"}, err)
+	}
+}
+
+func seed(n int64) *int64 {
+	return &n
+}
+
+func TestNewDeckSeeded(t *testing.T) {
+	first, _ := New(&NewDeckOpts{Shuffle: true, Decks: 2, Seed: seed(987654321)})
+	second, _ := New(&NewDeckOpts{Shuffle: true, Decks: 2, Seed: seed(987654321)})
+	if !cmp.Equal(first, second) {
+		t.Errorf("Failed for same seed: expected %v, got %v", first, second)
+	}
+	other, _ := New(&NewDeckOpts{Shuffle: true, Decks: 2, Seed: seed(987654322)})
+	if cmp.Equal(first, other) {
+		t.Errorf("Failed for different seed: expected a different order, got %v", other)
+	}
+}
+
+type DrawTest struct {
+	opts              DrawOpts
+	expectedDrawn     Deck
+	expectedRemaining Deck
+	expectedErr       error
+}
+
+func TestDraw(t *testing.T) {
+	d := Deck{
+		{Value: Queen, Suit: Spades},
+		{Value: Three, Suit: Clubs},
+		{Value: Five, Suit: Hearts},
+		{Value: Seven, Suit: Spades},
+	}
+	tests := []DrawTest{
+		{
+			DrawOpts{NumberOfCards: 1},
+			Deck{{Value: Queen, Suit: Spades}},
+			Deck{{Value: Three, Suit: Clubs}, {Value: Five, Suit: Hearts}, {Value: Seven, Suit: Spades}},
+			nil,
+		},
+		{
+			DrawOpts{Mode: DrawBottom, NumberOfCards: 2},
+			Deck{{Value: Seven, Suit: Spades}, {Value: Five, Suit: Hearts}},
+			Deck{{Value: Queen, Suit: Spades}, {Value: Three, Suit: Clubs}},
+			nil,
+		},
+		{
+			DrawOpts{Mode: DrawBottom, NumberOfCards: 5},
+			nil,
+			nil,
+			ErrDrawCardsSizeExceeded{},
+		},
+		{
+			DrawOpts{Cards: []string{"5H"}},
+			Deck{{Value: Five, Suit: Hearts}},
+			Deck{{Value: Queen, Suit: Spades}, {Value: Three, Suit: Clubs}, {Value: Seven, Suit: Spades}},
+			nil,
+		},
+		{
+			DrawOpts{Cards: []string{"AH"}},
+			nil,
+			nil,
+			ErrCardNotInDeck{CardCode: "AH"},
+		},
+		{
+			DrawOpts{Mode: "middle", NumberOfCards: 1},
+			nil,
+			nil,
+			ErrUnknownDrawMode{Mode: "middle"},
+		},
+	}
+
+	for _, test := range tests {
+		drawn, remaining, err := Draw(d, &test.opts, nil)
+		if !cmp.Equal(drawn, test.expectedDrawn) {
+			t.Errorf("Failed for %+v: expected drawn deck to be %v, got %v", test.opts, test.expectedDrawn, drawn)
+		}
+		if !cmp.Equal(remaining, test.expectedRemaining) {
+			t.Errorf("Failed for %+v: expected remaining deck to be %v, got %v", test.opts, test.expectedRemaining, remaining)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for %+v: expected error to be %v, got %v", test.opts, test.expectedErr, err)
+		}
+	}
+}
+
+func TestDrawRandomCards(t *testing.T) {
+	d := getShuffledDefaultDeck()
+	drawn, remaining, err := DrawRandomCards(d, 10, rand.New(rand.NewSource(7)))
+	if err != nil {
+		t.Errorf("Failed for random draw: expected error to be %v, got %v", nil, err)
+	}
+	if len(drawn) != 10 || len(remaining) != 42 {
+		t.Errorf("Failed for random draw: expected 10 drawn and 42 remaining, got %d and %d", len(drawn), len(remaining))
+	}
+	if !sameCards(append(append(Deck{}, drawn...), remaining...), d) {
+		t.Errorf("Failed for random draw: expected drawn and remaining cards to make up the deck, got %v and %v", drawn, remaining)
+	}
+	if cmp.Equal(drawn, d[:10]) {
+		t.Errorf("Failed for random draw: expected cards other than the top 10, got %v", drawn)
+	}
+	if len(d) != 52 {
+		t.Errorf("Failed for random draw: expected input deck to be unchanged, got %v", d)
+	}
+
+	_, _, err = DrawRandomCards(d, 53, rand.New(rand.NewSource(7)))
+	if err != (ErrDrawCardsSizeExceeded{}) {
+		t.Errorf("Failed for random draw: expected error to be %v, got %v", ErrDrawCardsSizeExceeded{}, err)
+	}
+}
+
+type PeekTest struct {
+	mode        DrawMode
+	n           int
+	expected    Deck
+	expectedErr error
+}
+
+func TestPeek(t *testing.T) {
+	d := Deck{
+		{Value: Queen, Suit: Spades},
+		{Value: Three, Suit: Clubs},
+		{Value: Five, Suit: Hearts},
+	}
+	tests := []PeekTest{
+		{"", 2, Deck{{Value: Queen, Suit: Spades}, {Value: Three, Suit: Clubs}}, nil},
+		{DrawTop, 3, d, nil},
+		{DrawBottom, 1, Deck{{Value: Five, Suit: Hearts}}, nil},
+		{DrawBottom, 4, nil, ErrDrawCardsSizeExceeded{}},
+		{DrawRandom, 1, nil, ErrUnknownDrawMode{Mode: DrawRandom}},
+	}
+	for _, test := range tests {
+		peeked, err := Peek(d, test.mode, test.n)
+		if !cmp.Equal(peeked, test.expected) {
+			t.Errorf("Failed for mode '%s' and %d cards: expected %v, got %v", test.mode, test.n, test.expected, peeked)
+		}
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for mode '%s' and %d cards: expected error %v, got %v", test.mode, test.n, test.expectedErr, err)
+		}
+		if len(peeked) > 0 {
+			peeked[0] = BlackJoker
+			if d[0] == BlackJoker {
+				t.Errorf("Failed for mode '%s' and %d cards: expected the peeked cards not to share memory with the deck", test.mode, test.n)
+			}
+		}
+	}
 }
@@ -60,9 +60,7 @@ func getDefaultDeck() Deck {
 }
 
 func getShuffledDefaultDeck() Deck {
-	rand.Seed(123)
 	d := getDefaultDeck()
-	d.Shuffle()
-	rand.Seed(1)
+	d.Shuffle(rand.New(rand.NewSource(123)))
 	return d
 }
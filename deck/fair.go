@@ -0,0 +1,108 @@
+package deck
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+// RNGFair shuffles a deck exactly once, when it is created, with a source
+// derived from a server seed and a client seed. The server publishes a
+// commitment to its seed before play and reveals the seed afterwards, so
+// players can check that the order was fixed in advance.
+const RNGFair RNG = "fair"
+
+var (
+	ErrSeedWithFairRNG   = errors.New("A seed cannot be used with the fair RNG")
+	ErrClientSeedMissing = errors.New("A client seed must be provided for the fair RNG")
+	ErrFairRandomDraw    = errors.New("Cards cannot be drawn from random positions of a provably fair deck")
+)
+
+// NewServerSeed returns a new random server seed as 64 hex characters.
+func NewServerSeed() string {
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Commitment returns the hex encoded SHA-256 hash of a server seed. It can be
+// published without giving away the seed.
+func Commitment(serverSeed string) string {
+	sum := sha256.Sum256([]byte(serverSeed))
+	return hex.EncodeToString(sum[:])
+}
+
+// fairSource is a deterministic Source. Its byte stream is the concatenation
+// of HMAC-SHA256(key=serverSeed, message=clientSeed+":"+i) for i = 0, 1, 2...
+// read as little endian uint64s.
+type fairSource struct {
+	serverSeed string
+	clientSeed string
+	counter    uint64
+	block      []byte
+}
+
+// NewFairSource returns the Source used for provably fair shuffles.
+func NewFairSource(serverSeed, clientSeed string) Source {
+	return &fairSource{serverSeed: serverSeed, clientSeed: clientSeed}
+}
+
+func (f *fairSource) uint64() uint64 {
+	if len(f.block) < 8 {
+		mac := hmac.New(sha256.New, []byte(f.serverSeed))
+		mac.Write([]byte(f.clientSeed + ":" + strconv.FormatUint(f.counter, 10)))
+		f.block = mac.Sum(nil)
+		f.counter++
+	}
+	v := binary.LittleEndian.Uint64(f.block[:8])
+	f.block = f.block[8:]
+	return v
+}
+
+// Intn rejects values below 2^64 mod n, which leaves a range whose size is a
+// multiple of n, and returns the first accepted value modulo n. Every result
+// is therefore equally likely.
+func (f *fairSource) Intn(n int) int {
+	bound := uint64(n)
+	threshold := -bound % bound
+	for {
+		v := f.uint64()
+		if v >= threshold {
+			return int(v % bound)
+		}
+	}
+}
+
+// Shuffle is a Fisher–Yates shuffle running from the last element down:
+// element i is swapped with element Intn(i+1).
+func (f *fairSource) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, f.Intn(i+1))
+	}
+}
+
+// VerifyFairShuffle reports whether serverSeed matches the published
+// commitment and shuffling the unshuffled deck with serverSeed and clientSeed
+// gives exactly the shuffled deck.
+func VerifyFairShuffle(serverSeed, commitment, clientSeed string, unshuffled, shuffled Deck) bool {
+	if !hmac.Equal([]byte(Commitment(serverSeed)), []byte(commitment)) {
+		return false
+	}
+	if len(unshuffled) != len(shuffled) {
+		return false
+	}
+	expected := append(Deck{}, unshuffled...)
+	expected.Shuffle(NewFairSource(serverSeed, clientSeed))
+	for i := range expected {
+		if expected[i] != shuffled[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package deck
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	testServerSeed = "5e1f0a9c3d2b4e6f8a7c9d0b1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f"
+	testClientSeed = "player-one"
+)
+
+func TestCommitment(t *testing.T) {
+	commitment := Commitment(testServerSeed)
+	if len(commitment) != 64 {
+		t.Errorf("Failed for commitment: expected 64 hex characters, got %s", commitment)
+	}
+	if commitment != Commitment(testServerSeed) {
+		t.Errorf("Failed for commitment: expected the same hash for the same seed")
+	}
+	if commitment == Commitment(NewServerSeed()) {
+		t.Errorf("Failed for commitment: expected a different hash for a different seed")
+	}
+}
+
+func TestNewFairDeck(t *testing.T) {
+	opts := NewDeckOpts{Shuffle: true, RNG: RNGFair, ServerSeed: testServerSeed, ClientSeed: testClientSeed}
+	first, err := New(&opts)
+	if err != nil {
+		t.Fatalf("Failed for fair deck: expected no error, got %v", err)
+	}
+	second, _ := New(&opts)
+	if !cmp.Equal(first, second) {
+		t.Errorf("Failed for fair deck: expected the same seeds to give the same order, got %v and %v", first, second)
+	}
+	opts.ClientSeed = "player-two"
+	other, _ := New(&opts)
+	if cmp.Equal(first, other) {
+		t.Errorf("Failed for fair deck: expected a different client seed to give a different order")
+	}
+
+	errTests := []struct {
+		opts        NewDeckOpts
+		expectedErr error
+	}{
+		{NewDeckOpts{Shuffle: true, RNG: RNGFair, ServerSeed: testServerSeed}, ErrClientSeedMissing},
+		{NewDeckOpts{Shuffle: true, RNG: RNGFair, ServerSeed: testServerSeed, ClientSeed: testClientSeed, Seed: seed(1)}, ErrSeedWithFairRNG},
+	}
+	for _, test := range errTests {
+		_, err := New(&test.opts)
+		if err != test.expectedErr {
+			t.Errorf("Failed for %+v: expected error %v, got %v", test.opts, test.expectedErr, err)
+		}
+	}
+}
+
+func TestVerifyFairShuffle(t *testing.T) {
+	unshuffled, _ := New(&NewDeckOpts{RNG: RNGFair, ServerSeed: testServerSeed, ClientSeed: testClientSeed})
+	shuffled, _ := New(&NewDeckOpts{Shuffle: true, RNG: RNGFair, ServerSeed: testServerSeed, ClientSeed: testClientSeed})
+	commitment := Commitment(testServerSeed)
+
+	swapped := append(Deck{}, shuffled...)
+	swapped[0], swapped[1] = swapped[1], swapped[0]
+
+	tests := []struct {
+		name       string
+		serverSeed string
+		commitment string
+		clientSeed string
+		shuffled   Deck
+		expected   bool
+	}{
+		{"valid", testServerSeed, commitment, testClientSeed, shuffled, true},
+		{"wrong server seed", NewServerSeed(), commitment, testClientSeed, shuffled, false},
+		{"wrong commitment", testServerSeed, Commitment("other"), testClientSeed, shuffled, false},
+		{"wrong client seed", testServerSeed, commitment, "player-two", shuffled, false},
+		{"tampered order", testServerSeed, commitment, testClientSeed, swapped, false},
+		{"missing card", testServerSeed, commitment, testClientSeed, shuffled[1:], false},
+	}
+	for _, test := range tests {
+		got := VerifyFairShuffle(test.serverSeed, test.commitment, test.clientSeed, unshuffled, test.shuffled)
+		if got != test.expected {
+			t.Errorf("Failed for %s case: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestFairShuffleIsUniform(t *testing.T) {
+	const trials = 60000
+	src := NewFairSource(testServerSeed, testClientSeed)
+	counts := make(map[string]int)
+	for i := 0; i < trials; i++ {
+		d := Deck{{Value: Ace, Suit: Spades}, {Value: Two, Suit: Spades}, {Value: Three, Suit: Spades}}
+		d.Shuffle(src)
+		counts[d[0].Code()+d[1].Code()+d[2].Code()]++
+	}
+	if len(counts) != 6 {
+		t.Errorf("Failed for fair shuffle: expected all 6 orders, got %v", counts)
+	}
+	for order, count := range counts {
+		if count < trials/6-600 || count > trials/6+600 {
+			t.Errorf("Failed for fair shuffle: order %s came up %d times out of %d", order, count, trials)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Family names a kind of deck, with its own suits and values.
+type Family string
+
+const (
+	// FamilyFrench is the 52 card deck with Spades, Diamonds, Clubs and
+	// Hearts.
+	FamilyFrench Family = "french"
+	// FamilyTarot is the 78 card French-suited tarot deck: Ace to Ten,
+	// Jack, Knight, Queen and King in every French suit, and the Trumps
+	// suit with Trump1 to Trump21 and the Fool.
+	FamilyTarot Family = "tarot"
+	// FamilyLatin is the 40 card Italian and Spanish deck: Ace to Seven,
+	// Jack, Knight and King of Cups, Coins, Swords and Batons.
+	FamilyLatin Family = "latin"
+	// FamilyGerman is the 32 card German deck: Seven to Ten, Unter, Ober,
+	// King and Ace of Acorns, Leaves, Hearts and Bells.
+	FamilyGerman Family = "german"
+)
+
+// familyDeck describes a family: the values it has in each of its suits, in
+// deck order, and the cards it has outside of them.
+type familyDeck struct {
+	suits  []CardSuit
+	values []CardValue
+	extra  Deck
+}
+
+var familyDecks = map[Family]familyDeck{
+	FamilyFrench: {
+		suits:  []CardSuit{Spades, Diamonds, Clubs, Hearts},
+		values: valuesBetween(Ace, King),
+	},
+	FamilyTarot: {
+		suits:  []CardSuit{Spades, Diamonds, Clubs, Hearts},
+		values: append(valuesBetween(Ace, Jack), Knight, Queen, King),
+		extra:  trumpsGenerator(),
+	},
+	FamilyLatin: {
+		suits:  []CardSuit{Cups, Coins, Swords, Batons},
+		values: append(valuesBetween(Ace, Seven), Jack, Knight, King),
+	},
+	FamilyGerman: {
+		suits:  []CardSuit{Acorns, Leaves, Hearts, Bells},
+		values: append(valuesBetween(Seven, Ten), Unter, Ober, King, Ace),
+	},
+}
+
+// familyCards holds every card of every family, and the jokers. Only these
+// cards can be decoded from their code.
+var familyCards = allFamilyCards()
+
+var (
+	ErrFamilyWithCustomDeck = errors.New("A deck family cannot be combined with a custom deck")
+	ErrPresetWithFamily     = errors.New("Presets can only be used with the french deck family")
+)
+
+type ErrUnknownFamily struct {
+	Family Family
+}
+
+func (e ErrUnknownFamily) Error() string {
+	return fmt.Sprintf("Deck family %s is unknown", e.Family)
+}
+
+// trumpsGenerator returns the Trumps suit of a tarot deck: Trump1 to Trump21
+// followed by the Fool.
+func trumpsGenerator() Deck {
+	trumps := make(Deck, 0, 22)
+	for _, value := range valuesBetween(Trump1, Trump21) {
+		trumps = append(trumps, Card{Value: value, Suit: Trumps})
+	}
+	return append(trumps, Card{Value: Fool, Suit: Trumps})
+}
+
+func familyDeckGenerator(family Family) (Deck, error) {
+	spec, ok := familyDecks[family]
+	if !ok {
+		return Deck{}, ErrUnknownFamily{Family: family}
+	}
+	newDeck := make(Deck, 0, len(spec.suits)*len(spec.values)+len(spec.extra))
+	for _, suit := range spec.suits {
+		for _, value := range spec.values {
+			newDeck = append(newDeck, Card{Suit: suit, Value: value})
+		}
+	}
+	return append(newDeck, spec.extra...), nil
+}
+
+func allFamilyCards() map[Card]bool {
+	cards := map[Card]bool{BlackJoker: true, RedJoker: true}
+	for family := range familyDecks {
+		familyDeck, _ := familyDeckGenerator(family)
+		for _, card := range familyDeck {
+			cards[card] = true
+		}
+	}
+	return cards
+}
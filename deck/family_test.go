@@ -0,0 +1,116 @@
+package deck
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewDeckFamily(t *testing.T) {
+	tests := []struct {
+		family        Family
+		expectedSize  int
+		expectedFirst Card
+		expectedLast  Card
+	}{
+		{family: FamilyFrench, expectedSize: 52, expectedFirst: Card{Value: Ace, Suit: Spades}, expectedLast: Card{Value: King, Suit: Hearts}},
+		{family: FamilyTarot, expectedSize: 78, expectedFirst: Card{Value: Ace, Suit: Spades}, expectedLast: Card{Value: Fool, Suit: Trumps}},
+		{family: FamilyLatin, expectedSize: 40, expectedFirst: Card{Value: Ace, Suit: Cups}, expectedLast: Card{Value: King, Suit: Batons}},
+		{family: FamilyGerman, expectedSize: 32, expectedFirst: Card{Value: Seven, Suit: Acorns}, expectedLast: Card{Value: Ace, Suit: Bells}},
+	}
+	for _, test := range tests {
+		output, err := New(&NewDeckOpts{Family: test.family})
+		if err != nil {
+			t.Errorf("Failed for %s family: expected error to be %v, got %v", test.family, nil, err)
+		}
+		if len(output) != test.expectedSize {
+			t.Errorf("Failed for %s family: expected %d cards, got %d", test.family, test.expectedSize, len(output))
+		}
+		if output[0] != test.expectedFirst || output[len(output)-1] != test.expectedLast {
+			t.Errorf("Failed for %s family: expected deck from %v to %v, got %v to %v", test.family, test.expectedFirst, test.expectedLast, output[0], output[len(output)-1])
+		}
+		for card, count := range countCards(output) {
+			if count != 1 {
+				t.Errorf("Failed for %s family: expected card %v once, got %d times", test.family, card, count)
+			}
+			value, suit, err := DecodeValueAndSuit(card.Code())
+			if value != card.Value || suit != card.Suit || err != nil {
+				t.Errorf("Failed for %s family: expected code %s to decode to %v, got %v of %v and error %v", test.family, card.Code(), card, value, suit, err)
+			}
+		}
+	}
+
+	french, _ := New(&NewDeckOpts{Family: FamilyFrench})
+	if !cmp.Equal(french, getDefaultDeck()) {
+		t.Errorf("Failed for french family: expected the default deck, got %v", french)
+	}
+	tarot, _ := New(&NewDeckOpts{Family: FamilyTarot})
+	trumps := 0
+	for _, card := range tarot {
+		if card.Suit == Trumps && card.Value != Fool {
+			trumps++
+		}
+	}
+	if trumps != 21 {
+		t.Errorf("Failed for tarot family: expected 21 trumps, got %d", trumps)
+	}
+}
+
+func TestNewDeckFamilyErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputOpts   NewDeckOpts
+		expectedErr error
+	}{
+		{name: "unknown family", inputOpts: NewDeckOpts{Family: "hanafuda"}, expectedErr: ErrUnknownFamily{Family: "hanafuda"}},
+		{name: "family with custom deck", inputOpts: NewDeckOpts{Family: FamilyLatin, CustomDeck: true, CustomDeckCards: []string{"AP"}}, expectedErr: ErrFamilyWithCustomDeck},
+		{name: "preset with family", inputOpts: NewDeckOpts{Family: FamilyTarot, Preset: PresetPiquet}, expectedErr: ErrPresetWithFamily},
+		{name: "everything excluded", inputOpts: NewDeckOpts{Family: FamilyGerman, ExcludeSuits: []CardSuit{Acorns, Leaves, Hearts, Bells}}, expectedErr: ErrEmptyDeck},
+	}
+	for _, test := range tests {
+		output, err := New(&test.inputOpts)
+		if err != test.expectedErr {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+		if len(output) != 0 {
+			t.Errorf("Failed for %s case: expected no cards, got %v", test.name, output)
+		}
+	}
+
+	output, err := New(&NewDeckOpts{Family: FamilyTarot, ExcludeSuits: []CardSuit{Spades, Diamonds, Clubs, Hearts}})
+	if len(output) != 22 || err != nil {
+		t.Errorf("Failed for tarot without french suits: expected 22 cards and error %v, got %d and %v", nil, len(output), err)
+	}
+}
+
+func TestFamilyCardCodes(t *testing.T) {
+	tests := []struct {
+		card         Card
+		expectedCode string
+		expectedJSON CardJSON
+	}{
+		{card: Card{Value: Knight, Suit: Hearts}, expectedCode: "NH", expectedJSON: CardJSON{Value: "KNIGHT", Suit: "HEARTS", Code: "NH"}},
+		{card: Card{Value: Trump1, Suit: Trumps}, expectedCode: "1T", expectedJSON: CardJSON{Value: "1", Suit: "TRUMPS", Code: "1T"}},
+		{card: Card{Value: Trump21, Suit: Trumps}, expectedCode: "21T", expectedJSON: CardJSON{Value: "21", Suit: "TRUMPS", Code: "21T"}},
+		{card: Card{Value: Fool, Suit: Trumps}, expectedCode: "FT", expectedJSON: CardJSON{Value: "FOOL", Suit: "TRUMPS", Code: "FT"}},
+		{card: Card{Value: Seven, Suit: Coins}, expectedCode: "7O", expectedJSON: CardJSON{Value: "7", Suit: "COINS", Code: "7O"}},
+		{card: Card{Value: Knight, Suit: Batons}, expectedCode: "NB", expectedJSON: CardJSON{Value: "KNIGHT", Suit: "BATONS", Code: "NB"}},
+		{card: Card{Value: Ober, Suit: Acorns}, expectedCode: "OA", expectedJSON: CardJSON{Value: "OBER", Suit: "ACORNS", Code: "OA"}},
+		{card: Card{Value: Unter, Suit: Hearts}, expectedCode: "UH", expectedJSON: CardJSON{Value: "UNTER", Suit: "HEARTS", Code: "UH"}},
+		{card: Card{Value: Ten, Suit: Bells}, expectedCode: "0E", expectedJSON: CardJSON{Value: "10", Suit: "BELLS", Code: "0E"}},
+	}
+	for _, test := range tests {
+		if code := test.card.Code(); code != test.expectedCode {
+			t.Errorf("Failed for %v: expected code to be %s, got %s", test.card, test.expectedCode, code)
+		}
+		if cardJSON := test.card.ToCardJSON(); cardJSON != test.expectedJSON {
+			t.Errorf("Failed for %v: expected JSON to be %v, got %v", test.card, test.expectedJSON, cardJSON)
+		}
+	}
+
+	for _, code := range []string{"US", "OD", "8P", "QW", "NE", "22T", "0T", "AT", "KT"} {
+		if _, _, err := DecodeValueAndSuit(code); err != (ErrInvalidCardCode{CardCode: code}) {
+			t.Errorf("Failed for code %s of a card in no family: expected error to be %v, got %v", code, ErrInvalidCardCode{CardCode: code}, err)
+		}
+	}
+}
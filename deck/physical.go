@@ -0,0 +1,162 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MaxPasses is the largest number of times a shuffle method can be repeated
+// in a single shuffle.
+const MaxPasses = 100
+
+// MaxOverhandPasses is the largest number of passes of ShuffleOverhand, which
+// needs far more of them than the other methods.
+const MaxOverhandPasses = 10000
+
+// ShuffleMethod names the way a deck is shuffled.
+type ShuffleMethod string
+
+const (
+	// ShuffleRandom puts the deck in a uniformly random order. Passes have
+	// no effect on it.
+	ShuffleRandom ShuffleMethod = "random"
+	// ShuffleRiffle is a Gilbert–Shannon–Reeds riffle shuffle. About seven
+	// passes are needed before a 52 card deck is close to random.
+	ShuffleRiffle ShuffleMethod = "riffle"
+	// ShuffleOverhand is an overhand shuffle. It mixes far more slowly than
+	// a riffle: thousands of passes are needed to randomize a full deck, so
+	// it can be repeated up to MaxOverhandPasses times.
+	ShuffleOverhand ShuffleMethod = "overhand"
+	// ShuffleCut cuts the deck near the middle.
+	ShuffleCut ShuffleMethod = "cut"
+)
+
+// overhandBreakOdds is the chance, as 1 in overhandBreakOdds, that a packet
+// ends after any given card of an overhand shuffle.
+const overhandBreakOdds = 5
+
+var ErrMethodWithFairRNG = errors.New("The fair RNG can only be used with the random shuffle method")
+
+type ErrUnknownShuffleMethod struct {
+	Method ShuffleMethod
+}
+
+func (e ErrUnknownShuffleMethod) Error() string {
+	return fmt.Sprintf("Shuffle method %s is unknown", e.Method)
+}
+
+type ErrInvalidPasses struct {
+	Passes int
+	Max    int
+}
+
+func (e ErrInvalidPasses) Error() string {
+	return fmt.Sprintf("Number of passes must be between 1 and %d, got %d", e.Max, e.Passes)
+}
+
+type ErrInvalidCutPosition struct {
+	Position int
+	Size     int
+}
+
+func (e ErrInvalidCutPosition) Error() string {
+	return fmt.Sprintf("Cut position must be between 0 and %d, got %d", e.Size, e.Position)
+}
+
+// ShuffleBy shuffles d passes times with the given method. The zero method
+// means ShuffleRandom and zero passes means one.
+func (d Deck) ShuffleBy(method ShuffleMethod, passes int, src Source) error {
+	if err := checkShuffle(method, passes); err != nil {
+		return err
+	}
+	if passes == 0 {
+		passes = 1
+	}
+	var pass func(Source)
+	switch method {
+	case "", ShuffleRandom:
+		d.Shuffle(src)
+		return nil
+	case ShuffleRiffle:
+		pass = d.Riffle
+	case ShuffleOverhand:
+		pass = d.Overhand
+	case ShuffleCut:
+		pass = func(src Source) {
+			d.Cut(binomialHalf(len(d), src))
+		}
+	}
+	for i := 0; i < passes; i++ {
+		pass(src)
+	}
+	return nil
+}
+
+// checkShuffle returns an error if method is unknown or cannot be repeated
+// passes times. Zero passes means one.
+func checkShuffle(method ShuffleMethod, passes int) error {
+	max := MaxPasses
+	switch method {
+	case "", ShuffleRandom, ShuffleRiffle, ShuffleCut:
+	case ShuffleOverhand:
+		max = MaxOverhandPasses
+	default:
+		return ErrUnknownShuffleMethod{Method: method}
+	}
+	if passes < 0 || passes > max {
+		return ErrInvalidPasses{Passes: passes, Max: max}
+	}
+	return nil
+}
+
+// Riffle does one Gilbert–Shannon–Reeds riffle shuffle: the deck is cut into
+// two packets, with the size of the top packet binomially distributed, and
+// the packets are interleaved by dropping the next card from a packet with
+// probability proportional to its current size.
+func (d Deck) Riffle(src Source) {
+	cut := binomialHalf(len(d), src)
+	top := append(Deck{}, d[:cut]...)
+	bottom := append(Deck{}, d[cut:]...)
+	for i := range d {
+		if src.Intn(len(top)+len(bottom)) < len(top) {
+			d[i], top = top[0], top[1:]
+		} else {
+			d[i], bottom = bottom[0], bottom[1:]
+		}
+	}
+}
+
+// Overhand does one overhand shuffle: small packets are taken off the top of
+// the deck and each is dropped on top of the ones before it, which reverses
+// the order of the packets but not of the cards within them.
+func (d Deck) Overhand(src Source) {
+	shuffled := make(Deck, len(d))
+	start, end := 0, len(d)
+	for i := 1; i <= len(d); i++ {
+		if i == len(d) || src.Intn(overhandBreakOdds) == 0 {
+			end -= copy(shuffled[end-(i-start):end], d[start:i])
+			start = i
+		}
+	}
+	copy(d, shuffled)
+}
+
+// Cut moves the top position cards to the bottom of the deck.
+func (d Deck) Cut(position int) error {
+	if position < 0 || position > len(d) {
+		return ErrInvalidCutPosition{Position: position, Size: len(d)}
+	}
+	cut := append(append(Deck{}, d[position:]...), d[:position]...)
+	copy(d, cut)
+	return nil
+}
+
+// binomialHalf returns the number of heads in n fair coin flips, the position
+// at which a person cutting a deck of n cards "near the middle" cuts it.
+func binomialHalf(n int, src Source) int {
+	heads := 0
+	for i := 0; i < n; i++ {
+		heads += src.Intn(2)
+	}
+	return heads
+}
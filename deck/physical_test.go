@@ -0,0 +1,165 @@
+package deck
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func getSpadesDeck() Deck {
+	d := Deck{}
+	for v := Ace; v <= King; v++ {
+		d = append(d, Card{Value: v, Suit: Spades})
+	}
+	return d
+}
+
+func sameCards(a, b Deck) bool {
+	counts := make(map[Card]int)
+	for _, c := range a {
+		counts[c]++
+	}
+	for _, c := range b {
+		counts[c]--
+	}
+	for _, n := range counts {
+		if n != 0 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+type CutTest struct {
+	position    int
+	expected    Deck
+	expectedErr error
+}
+
+func TestCut(t *testing.T) {
+	d := Deck{{Value: Ace, Suit: Spades}, {Value: Two, Suit: Spades}, {Value: Three, Suit: Spades}}
+	tests := []CutTest{
+		{0, d, nil},
+		{1, Deck{{Value: Two, Suit: Spades}, {Value: Three, Suit: Spades}, {Value: Ace, Suit: Spades}}, nil},
+		{3, d, nil},
+		{4, d, ErrInvalidCutPosition{Position: 4, Size: 3}},
+		{-1, d, ErrInvalidCutPosition{Position: -1, Size: 3}},
+	}
+	for _, test := range tests {
+		cut := append(Deck{}, d...)
+		err := cut.Cut(test.position)
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for position %d: expected error %v, got %v", test.position, test.expectedErr, err)
+		}
+		if !cmp.Equal(cut, test.expected) {
+			t.Errorf("Failed for position %d: expected %v, got %v", test.position, test.expected, cut)
+		}
+	}
+}
+
+// A single riffle leaves the cards of each packet in their original relative
+// order, so the deck is made of at most two interleaved rising sequences.
+func TestRiffleKeepsRisingSequences(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		d := getSpadesDeck()
+		d.Riffle(src)
+		if !sameCards(d, getSpadesDeck()) {
+			t.Fatalf("Failed for riffle: expected the same cards, got %v", d)
+		}
+		sequences := 1
+		position := make(map[CardValue]int)
+		for j, c := range d {
+			position[c.Value] = j
+		}
+		for v := Two; v <= King; v++ {
+			if position[v] < position[v-1] {
+				sequences++
+			}
+		}
+		if sequences > 2 {
+			t.Errorf("Failed for riffle: expected at most 2 rising sequences, got %d in %v", sequences, d)
+		}
+	}
+}
+
+// An overhand shuffle only reverses the order of packets, so the deck is made
+// of runs of consecutive cards, each run starting lower than the one before.
+func TestOverhandKeepsPackets(t *testing.T) {
+	src := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		d := getSpadesDeck()
+		d.Overhand(src)
+		if !sameCards(d, getSpadesDeck()) {
+			t.Fatalf("Failed for overhand: expected the same cards, got %v", d)
+		}
+		runStart := d[0].Value
+		for j := 1; j < len(d); j++ {
+			if d[j].Value == d[j-1].Value+1 {
+				continue
+			}
+			if d[j].Value >= runStart {
+				t.Errorf("Failed for overhand: expected packets in reverse order, got %v", d)
+				break
+			}
+			runStart = d[j].Value
+		}
+	}
+}
+
+type ShuffleByTest struct {
+	method      ShuffleMethod
+	passes      int
+	expectedErr error
+}
+
+func TestShuffleBy(t *testing.T) {
+	tests := []ShuffleByTest{
+		{"", 0, nil},
+		{ShuffleRandom, 3, nil},
+		{ShuffleRiffle, 7, nil},
+		{ShuffleOverhand, MaxOverhandPasses, nil},
+		{ShuffleCut, 1, nil},
+		{ShuffleRiffle, MaxPasses + 1, ErrInvalidPasses{Passes: MaxPasses + 1, Max: MaxPasses}},
+		{ShuffleRiffle, -1, ErrInvalidPasses{Passes: -1, Max: MaxPasses}},
+		{ShuffleOverhand, MaxOverhandPasses + 1, ErrInvalidPasses{Passes: MaxOverhandPasses + 1, Max: MaxOverhandPasses}},
+		{"pharaoh", 1, ErrUnknownShuffleMethod{Method: "pharaoh"}},
+	}
+	for _, test := range tests {
+		d := getSpadesDeck()
+		err := d.ShuffleBy(test.method, test.passes, rand.New(rand.NewSource(1)))
+		if !cmp.Equal(err, test.expectedErr) {
+			t.Errorf("Failed for method '%s' with %d passes: expected error %v, got %v", test.method, test.passes, test.expectedErr, err)
+		}
+		if !sameCards(d, getSpadesDeck()) {
+			t.Errorf("Failed for method '%s' with %d passes: expected the same cards, got %v", test.method, test.passes, d)
+		}
+	}
+}
+
+func TestNewDeckShuffleMethod(t *testing.T) {
+	opts := NewDeckOpts{Shuffle: true, ShuffleMethod: ShuffleRiffle, Passes: 7, Seed: seed(5)}
+	first, err := New(&opts)
+	if err != nil {
+		t.Fatalf("Failed for riffled deck: expected no error, got %v", err)
+	}
+	second, _ := New(&opts)
+	if !cmp.Equal(first, second) {
+		t.Errorf("Failed for riffled deck: expected the same seed to give the same order")
+	}
+
+	_, err = New(&NewDeckOpts{Shuffle: true, ShuffleMethod: ShuffleRiffle, RNG: RNGFair, ClientSeed: "client"})
+	if err != ErrMethodWithFairRNG {
+		t.Errorf("Failed for riffle with fair rng: expected error %v, got %v", ErrMethodWithFairRNG, err)
+	}
+
+	_, err = New(&NewDeckOpts{ShuffleMethod: "pharaoh"})
+	if expectedErr := (ErrUnknownShuffleMethod{Method: "pharaoh"}); !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for unknown method of unshuffled deck: expected error %v, got %v", expectedErr, err)
+	}
+	_, err = New(&NewDeckOpts{ShuffleMethod: ShuffleRiffle, Passes: -5})
+	if expectedErr := (ErrInvalidPasses{Passes: -5, Max: MaxPasses}); !cmp.Equal(err, expectedErr) {
+		t.Errorf("Failed for invalid passes of unshuffled deck: expected error %v, got %v", expectedErr, err)
+	}
+}
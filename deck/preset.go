@@ -0,0 +1,142 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Preset names a well known deck composition.
+type Preset string
+
+const (
+	// PresetStandard is the full 52 card deck.
+	PresetStandard Preset = "standard"
+	// PresetPiquet is the 32 card piquet deck: Sevens to Aces.
+	PresetPiquet Preset = "piquet"
+	// PresetEuchre is the 24 card euchre deck: Nines to Aces.
+	PresetEuchre Preset = "euchre"
+	// PresetPinochle is the 48 card double pinochle deck: two copies of
+	// every Nine to Ace.
+	PresetPinochle Preset = "pinochle"
+	// PresetStripped is the 40 card Spanish-style deck: the standard deck
+	// without Eights, Nines and Tens.
+	PresetStripped Preset = "stripped"
+)
+
+// presetDeck describes a preset: the values it has in every suit, and how
+// many copies of each card.
+type presetDeck struct {
+	values []CardValue
+	copies int
+}
+
+var presetDecks = map[Preset]presetDeck{
+	PresetStandard: {values: valuesBetween(Ace, King), copies: 1},
+	PresetPiquet:   {values: append([]CardValue{Ace}, valuesBetween(Seven, King)...), copies: 1},
+	PresetEuchre:   {values: append([]CardValue{Ace}, valuesBetween(Nine, King)...), copies: 1},
+	PresetPinochle: {values: append([]CardValue{Ace}, valuesBetween(Nine, King)...), copies: 2},
+	PresetStripped: {values: append(valuesBetween(Ace, Seven), Jack, Queen, King), copies: 1},
+}
+
+var (
+	ErrPresetWithCustomDeck = errors.New("A preset cannot be combined with a custom deck")
+	ErrEmptyDeck            = errors.New("Every card of the deck has been excluded")
+)
+
+type ErrUnknownPreset struct {
+	Preset Preset
+}
+
+func (e ErrUnknownPreset) Error() string {
+	return fmt.Sprintf("Deck preset %s is unknown", e.Preset)
+}
+
+type ErrInvalidValueCode struct {
+	Code string
+}
+
+func (e ErrInvalidValueCode) Error() string {
+	return fmt.Sprintf("Rank code %s is invalid", e.Code)
+}
+
+type ErrInvalidSuitCode struct {
+	Code string
+}
+
+func (e ErrInvalidSuitCode) Error() string {
+	return fmt.Sprintf("Suit code %s is invalid", e.Code)
+}
+
+func valuesBetween(from, to CardValue) []CardValue {
+	values := make([]CardValue, 0, to-from+1)
+	for v := from; v <= to; v++ {
+		values = append(values, v)
+	}
+	return values
+}
+
+func presetDeckGenerator(preset Preset) (Deck, error) {
+	spec, ok := presetDecks[preset]
+	if !ok {
+		return Deck{}, ErrUnknownPreset{Preset: preset}
+	}
+	newDeck := make(Deck, 0, len(spec.values)*4*spec.copies)
+	for i := Spades; i <= Hearts; i++ {
+		for _, value := range spec.values {
+			for c := 0; c < spec.copies; c++ {
+				newDeck = append(newDeck, Card{Suit: i, Value: value})
+			}
+		}
+	}
+	return newDeck, nil
+}
+
+// excludeCards returns the cards of d that have none of the given values
+// and suits.
+func excludeCards(d Deck, values []CardValue, suits []CardSuit) Deck {
+	remaining := make(Deck, 0, len(d))
+	for _, card := range d {
+		if !containsValue(values, card.Value) && !containsSuit(suits, card.Suit) {
+			remaining = append(remaining, card)
+		}
+	}
+	return remaining
+}
+
+func containsValue(values []CardValue, value CardValue) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSuit(suits []CardSuit, suit CardSuit) bool {
+	for _, s := range suits {
+		if s == suit {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeValue parses a value code, such as "A", "9" or "10". It accepts the
+// same codes as DecodeValueAndSuit.
+func DecodeValue(code string) (CardValue, error) {
+	value, ok := valuesByCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return 0, ErrInvalidValueCode{Code: code}
+	}
+	return value, nil
+}
+
+// DecodeSuit parses a suit code, such as "S" or "h".
+func DecodeSuit(code string) (CardSuit, error) {
+	suit, ok := suitsByCode[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return 0, ErrInvalidSuitCode{Code: code}
+	}
+	return suit, nil
+}
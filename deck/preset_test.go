@@ -0,0 +1,127 @@
+package deck
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func countCards(d Deck) map[Card]int {
+	counts := make(map[Card]int)
+	for _, card := range d {
+		counts[card]++
+	}
+	return counts
+}
+
+func TestNewDeckPreset(t *testing.T) {
+	tests := []struct {
+		preset         Preset
+		expectedSize   int
+		expectedValues []CardValue
+		expectedCopies int
+	}{
+		{preset: PresetStandard, expectedSize: 52, expectedValues: valuesBetween(Ace, King), expectedCopies: 1},
+		{preset: PresetPiquet, expectedSize: 32, expectedValues: []CardValue{Ace, Seven, Eight, Nine, Ten, Jack, Queen, King}, expectedCopies: 1},
+		{preset: PresetEuchre, expectedSize: 24, expectedValues: []CardValue{Ace, Nine, Ten, Jack, Queen, King}, expectedCopies: 1},
+		{preset: PresetPinochle, expectedSize: 48, expectedValues: []CardValue{Ace, Nine, Ten, Jack, Queen, King}, expectedCopies: 2},
+		{preset: PresetStripped, expectedSize: 40, expectedValues: []CardValue{Ace, Two, Three, Four, Five, Six, Seven, Jack, Queen, King}, expectedCopies: 1},
+	}
+	for _, test := range tests {
+		output, err := New(&NewDeckOpts{Preset: test.preset})
+		if err != nil {
+			t.Errorf("Failed for %s preset: expected error to be %v, got %v", test.preset, nil, err)
+		}
+		if len(output) != test.expectedSize {
+			t.Errorf("Failed for %s preset: expected %d cards, got %d", test.preset, test.expectedSize, len(output))
+		}
+		expectedCounts := make(map[Card]int)
+		for suit := Spades; suit <= Hearts; suit++ {
+			for _, value := range test.expectedValues {
+				expectedCounts[Card{Value: value, Suit: suit}] = test.expectedCopies
+			}
+		}
+		if counts := countCards(output); !cmp.Equal(counts, expectedCounts) {
+			t.Errorf("Failed for %s preset: expected cards %v, got %v", test.preset, expectedCounts, counts)
+		}
+	}
+
+	standard, _ := New(&NewDeckOpts{Preset: PresetStandard})
+	if !cmp.Equal(standard, getDefaultDeck()) {
+		t.Errorf("Failed for standard preset: expected the default deck, got %v", standard)
+	}
+}
+
+func TestNewDeckExclusions(t *testing.T) {
+	tests := []struct {
+		name           string
+		inputOpts      NewDeckOpts
+		expectedOutput Deck
+		expectedErr    error
+	}{
+		{
+			name:      "exclude values and suits",
+			inputOpts: NewDeckOpts{Preset: PresetEuchre, ExcludeValues: []CardValue{Nine, Ten, Jack, Queen}, ExcludeSuits: []CardSuit{Diamonds, Clubs}},
+			expectedOutput: Deck{
+				{Value: Ace, Suit: Spades}, {Value: King, Suit: Spades},
+				{Value: Ace, Suit: Hearts}, {Value: King, Suit: Hearts},
+			},
+		},
+		{
+			name:           "exclude from custom deck",
+			inputOpts:      NewDeckOpts{CustomDeck: true, CustomDeckCards: []string{"AS", "2S", "AH"}, ExcludeValues: []CardValue{Ace}},
+			expectedOutput: Deck{{Value: Two, Suit: Spades}},
+		},
+		{
+			name:           "jokers are kept",
+			inputOpts:      NewDeckOpts{Preset: PresetEuchre, ExcludeSuits: []CardSuit{Spades, Diamonds, Clubs}, ExcludeValues: valuesBetween(Nine, King), IncludeJokers: true},
+			expectedOutput: Deck{{Value: Ace, Suit: Hearts}, BlackJoker, RedJoker},
+		},
+		{
+			name:           "everything excluded",
+			inputOpts:      NewDeckOpts{ExcludeSuits: []CardSuit{Spades, Diamonds, Clubs, Hearts}},
+			expectedOutput: Deck{},
+			expectedErr:    ErrEmptyDeck,
+		},
+		{
+			name:           "unknown preset",
+			inputOpts:      NewDeckOpts{Preset: "skat"},
+			expectedOutput: Deck{},
+			expectedErr:    ErrUnknownPreset{Preset: "skat"},
+		},
+		{
+			name:           "preset with custom deck",
+			inputOpts:      NewDeckOpts{Preset: PresetPiquet, CustomDeck: true, CustomDeckCards: []string{"AS"}},
+			expectedOutput: Deck{},
+			expectedErr:    ErrPresetWithCustomDeck,
+		},
+	}
+	for _, test := range tests {
+		output, err := New(&test.inputOpts)
+		if !cmp.Equal(output, test.expectedOutput) {
+			t.Errorf("Failed for %s case: expected deck to be %v, got %v", test.name, test.expectedOutput, output)
+		}
+		if err != test.expectedErr {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func TestDecodeValueAndDecodeSuit(t *testing.T) {
+	for code, expected := range map[string]CardValue{"A": Ace, "9": Nine, "10": Ten, "0": Ten, "q": Queen} {
+		if value, err := DecodeValue(code); value != expected || err != nil {
+			t.Errorf("Failed for value code %s: expected %v and error %v, got %v and %v", code, expected, nil, value, err)
+		}
+	}
+	if _, err := DecodeValue("11"); err != (ErrInvalidValueCode{Code: "11"}) {
+		t.Errorf("Failed for invalid value code: expected error to be %v, got %v", ErrInvalidValueCode{Code: "11"}, err)
+	}
+	for code, expected := range map[string]CardSuit{"S": Spades, "d": Diamonds, "C": Clubs, "H": Hearts} {
+		if suit, err := DecodeSuit(code); suit != expected || err != nil {
+			t.Errorf("Failed for suit code %s: expected %v and error %v, got %v and %v", code, expected, nil, suit, err)
+		}
+	}
+	if _, err := DecodeSuit("X"); err != (ErrInvalidSuitCode{Code: "X"}) {
+		t.Errorf("Failed for invalid suit code: expected error to be %v, got %v", ErrInvalidSuitCode{Code: "X"}, err)
+	}
+}
@@ -0,0 +1,140 @@
+package deck
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Ranking orders cards for game logic. The zero value ranks Aces high, has
+// no trump suit and orders suits as they are declared, Spades highest.
+type Ranking struct {
+	// AceLow ranks Aces below Twos instead of above Kings.
+	AceLow bool
+	// Trump, if set, is the suit whose cards rank above the cards of every
+	// other suit. Use Trumps for tarot decks.
+	Trump *CardSuit
+	// SuitOrder lists suits from highest to lowest. Suits that are not
+	// listed rank below the listed ones.
+	SuitOrder []CardSuit
+}
+
+// BridgeSuitOrder is the suit order of bridge bidding: Spades, Hearts,
+// Diamonds, Clubs.
+var BridgeSuitOrder = []CardSuit{Spades, Hearts, Diamonds, Clubs}
+
+// RankingName names the ways cards of a suit can be ranked.
+type RankingName string
+
+const (
+	RankAceHigh RankingName = "ace-high"
+	RankAceLow  RankingName = "ace-low"
+)
+
+type ErrUnknownRanking struct {
+	Name RankingName
+}
+
+func (e ErrUnknownRanking) Error() string {
+	return fmt.Sprintf("Ranking %s is unknown", e.Name)
+}
+
+// NewRanking returns the Ranking with the given name, trump suit and suit
+// order. The zero name means RankAceHigh.
+func NewRanking(name RankingName, trump *CardSuit, suitOrder []CardSuit) (Ranking, error) {
+	switch name {
+	case "", RankAceHigh:
+		return Ranking{Trump: trump, SuitOrder: suitOrder}, nil
+	case RankAceLow:
+		return Ranking{AceLow: true, Trump: trump, SuitOrder: suitOrder}, nil
+	default:
+		return Ranking{}, ErrUnknownRanking{Name: name}
+	}
+}
+
+// Compare returns -1 if a ranks below b, 1 if it ranks above b and 0 if they
+// rank the same. Jokers rank above every other card, the red one highest.
+// Otherwise trumps rank above other cards, cards of different suits are
+// ordered by suit, and cards of the same suit by value.
+func (r Ranking) Compare(a, b Card) int {
+	if a.IsJoker() || b.IsJoker() {
+		return compareInts(jokerRank(a), jokerRank(b))
+	}
+	if aTrump, bTrump := r.isTrump(a.Suit), r.isTrump(b.Suit); aTrump != bTrump {
+		if aTrump {
+			return 1
+		}
+		return -1
+	}
+	if a.Suit != b.Suit {
+		return compareInts(r.suitRank(a.Suit), r.suitRank(b.Suit))
+	}
+	return compareInts(r.valueRank(a), r.valueRank(b))
+}
+
+// Sort orders the cards of d from the highest to the lowest, the way a hand
+// is usually held. Cards that rank the same keep their order.
+func (d Deck) Sort(r Ranking) {
+	sort.SliceStable(d, func(i, j int) bool {
+		return r.Compare(d[i], d[j]) > 0
+	})
+}
+
+func (r Ranking) isTrump(suit CardSuit) bool {
+	return r.Trump != nil && *r.Trump == suit
+}
+
+// suitRank is higher for higher suits.
+func (r Ranking) suitRank(suit CardSuit) int {
+	for i, s := range r.SuitOrder {
+		if s == suit {
+			return len(r.SuitOrder) - i
+		}
+	}
+	return -int(suit)
+}
+
+// valueRank is higher for higher values of the same suit. Values of
+// different families that play the same role rank the same: Unter as Jack and
+// Ober as Queen. The Knight ranks between them.
+func (r Ranking) valueRank(c Card) int {
+	if c.Suit == Trumps {
+		// The Fool ranks below Trump1, and the trumps by their number.
+		return int(c.Value - Fool)
+	}
+	switch c.Value {
+	case Ace:
+		if r.AceLow {
+			return 1
+		}
+		return 15
+	case Jack, Unter:
+		return 11
+	case Knight:
+		return 12
+	case Queen, Ober:
+		return 13
+	case King:
+		return 14
+	}
+	return int(c.Value)
+}
+
+func jokerRank(c Card) int {
+	switch c {
+	case RedJoker:
+		return 2
+	case BlackJoker:
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
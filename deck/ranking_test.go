@@ -0,0 +1,94 @@
+package deck
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRankingCompare(t *testing.T) {
+	hearts, trumps := Hearts, Trumps
+	tests := []struct {
+		name     string
+		ranking  Ranking
+		a, b     Card
+		expected int
+	}{
+		{name: "ace high", ranking: Ranking{}, a: Card{Value: Ace, Suit: Spades}, b: Card{Value: King, Suit: Spades}, expected: 1},
+		{name: "ace low", ranking: Ranking{AceLow: true}, a: Card{Value: Ace, Suit: Spades}, b: Card{Value: Two, Suit: Spades}, expected: -1},
+		{name: "same card", ranking: Ranking{}, a: Card{Value: Ten, Suit: Clubs}, b: Card{Value: Ten, Suit: Clubs}, expected: 0},
+		{name: "declared suit order", ranking: Ranking{}, a: Card{Value: Two, Suit: Spades}, b: Card{Value: Ace, Suit: Hearts}, expected: 1},
+		{name: "bridge suit order", ranking: Ranking{SuitOrder: BridgeSuitOrder}, a: Card{Value: Two, Suit: Hearts}, b: Card{Value: Ace, Suit: Diamonds}, expected: 1},
+		{name: "unlisted suit", ranking: Ranking{SuitOrder: []CardSuit{Clubs}}, a: Card{Value: Ace, Suit: Spades}, b: Card{Value: Two, Suit: Clubs}, expected: -1},
+		{name: "trump", ranking: Ranking{Trump: &hearts, SuitOrder: BridgeSuitOrder}, a: Card{Value: Two, Suit: Hearts}, b: Card{Value: Ace, Suit: Spades}, expected: 1},
+		{name: "both trumps", ranking: Ranking{Trump: &hearts}, a: Card{Value: Two, Suit: Hearts}, b: Card{Value: Three, Suit: Hearts}, expected: -1},
+		{name: "joker", ranking: Ranking{Trump: &hearts}, a: Card{Value: Ace, Suit: Hearts}, b: BlackJoker, expected: -1},
+		{name: "red joker", ranking: Ranking{}, a: RedJoker, b: BlackJoker, expected: 1},
+		{name: "knight", ranking: Ranking{}, a: Card{Value: Knight, Suit: Cups}, b: Card{Value: Jack, Suit: Cups}, expected: 1},
+		{name: "queen over knight", ranking: Ranking{}, a: Card{Value: Knight, Suit: Spades}, b: Card{Value: Queen, Suit: Spades}, expected: -1},
+		{name: "ober over unter", ranking: Ranking{}, a: Card{Value: Ober, Suit: Acorns}, b: Card{Value: Unter, Suit: Acorns}, expected: 1},
+		{name: "tarot trumps", ranking: Ranking{Trump: &trumps}, a: Card{Value: Trump1, Suit: Trumps}, b: Card{Value: King, Suit: Spades}, expected: 1},
+		{name: "tarot trump order", ranking: Ranking{Trump: &trumps}, a: Card{Value: Trump21, Suit: Trumps}, b: Card{Value: Trump2, Suit: Trumps}, expected: 1},
+		{name: "fool", ranking: Ranking{Trump: &trumps}, a: Card{Value: Fool, Suit: Trumps}, b: Card{Value: Trump1, Suit: Trumps}, expected: -1},
+	}
+	for _, test := range tests {
+		if result := test.ranking.Compare(test.a, test.b); result != test.expected {
+			t.Errorf("Failed for %s case: expected %v compared to %v to be %d, got %d", test.name, test.a, test.b, test.expected, result)
+		}
+		if result := test.ranking.Compare(test.b, test.a); result != -test.expected {
+			t.Errorf("Failed for %s case: expected %v compared to %v to be %d, got %d", test.name, test.b, test.a, -test.expected, result)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	diamonds := Diamonds
+	hand := Deck{
+		{Value: Two, Suit: Clubs}, {Value: Ace, Suit: Diamonds}, {Value: King, Suit: Spades},
+		{Value: Ten, Suit: Hearts}, {Value: Ace, Suit: Spades}, {Value: Three, Suit: Diamonds},
+	}
+	tests := []struct {
+		name     string
+		ranking  Ranking
+		expected Deck
+	}{
+		{
+			name:    "bridge",
+			ranking: Ranking{SuitOrder: BridgeSuitOrder},
+			expected: Deck{
+				{Value: Ace, Suit: Spades}, {Value: King, Suit: Spades}, {Value: Ten, Suit: Hearts},
+				{Value: Ace, Suit: Diamonds}, {Value: Three, Suit: Diamonds}, {Value: Two, Suit: Clubs},
+			},
+		},
+		{
+			name:    "ace low with trump",
+			ranking: Ranking{AceLow: true, Trump: &diamonds, SuitOrder: BridgeSuitOrder},
+			expected: Deck{
+				{Value: Three, Suit: Diamonds}, {Value: Ace, Suit: Diamonds}, {Value: King, Suit: Spades},
+				{Value: Ace, Suit: Spades}, {Value: Ten, Suit: Hearts}, {Value: Two, Suit: Clubs},
+			},
+		},
+	}
+	for _, test := range tests {
+		sorted := append(Deck{}, hand...)
+		sorted.Sort(test.ranking)
+		if !cmp.Equal(sorted, test.expected) {
+			t.Errorf("Failed for %s case: expected %v, got %v", test.name, test.expected, sorted)
+		}
+	}
+}
+
+func TestNewRanking(t *testing.T) {
+	hearts := Hearts
+	ranking, err := NewRanking(RankAceLow, &hearts, BridgeSuitOrder)
+	expected := Ranking{AceLow: true, Trump: &hearts, SuitOrder: BridgeSuitOrder}
+	if !cmp.Equal(ranking, expected) || err != nil {
+		t.Errorf("Failed for ace low: expected %v and error %v, got %v and %v", expected, nil, ranking, err)
+	}
+	if ranking, err := NewRanking("", nil, nil); !cmp.Equal(ranking, Ranking{}) || err != nil {
+		t.Errorf("Failed for default: expected %v and error %v, got %v and %v", Ranking{}, nil, ranking, err)
+	}
+	if _, err := NewRanking("queen-high", nil, nil); err != (ErrUnknownRanking{Name: "queen-high"}) {
+		t.Errorf("Failed for unknown ranking: expected error to be %v, got %v", ErrUnknownRanking{Name: "queen-high"}, err)
+	}
+}
@@ -0,0 +1,93 @@
+package deck
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+)
+
+// RNG names a random number generator that decks can be shuffled with.
+type RNG string
+
+const (
+	// RNGMath is math/rand. It is fast and can be seeded for reproducible
+	// shuffles, but its output can be predicted.
+	RNGMath RNG = "math"
+	// RNGCrypto is crypto/rand. It cannot be seeded or predicted.
+	RNGCrypto RNG = "crypto"
+)
+
+var (
+	ErrSeedWithCryptoRNG = errors.New("A seed cannot be used with the crypto RNG")
+	ErrFairReshuffle     = errors.New("Provably fair decks can only be shuffled when they are created")
+)
+
+type ErrUnknownRNG struct {
+	RNG RNG
+}
+
+func (e ErrUnknownRNG) Error() string {
+	return fmt.Sprintf("RNG %s is unknown", e.RNG)
+}
+
+// Source is the randomness that decks are shuffled with. *rand.Rand is a
+// Source.
+type Source interface {
+	// Intn returns a uniformly distributed number in [0, n).
+	Intn(n int) int
+	// Shuffle randomizes the order of n elements using swap.
+	Shuffle(n int, swap func(i, j int))
+}
+
+// NewSource returns a Source backed by the given RNG. A seed can only be used
+// with RNGMath; a nil seed gives a math/rand source with an unpredictable
+// seed. RNGFair sources need seeds of their own and come from NewFairSource.
+func NewSource(rng RNG, seed *int64) (Source, error) {
+	switch rng {
+	case "", RNGMath:
+		if seed != nil {
+			return rand.New(rand.NewSource(*seed)), nil
+		}
+		return rand.New(rand.NewSource(randomSeed())), nil
+	case RNGCrypto:
+		if seed != nil {
+			return nil, ErrSeedWithCryptoRNG
+		}
+		return cryptoSource{}, nil
+	case RNGFair:
+		return nil, ErrFairReshuffle
+	default:
+		return nil, ErrUnknownRNG{RNG: rng}
+	}
+}
+
+func randomSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// cryptoSource draws from crypto/rand. crypto/rand.Int rejects out of range
+// samples instead of reducing them, so every result is equally likely.
+type cryptoSource struct{}
+
+func (cryptoSource) Intn(n int) int {
+	v, err := crand.Int(crand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		panic(err)
+	}
+	return int(v.Int64())
+}
+
+// Shuffle is a Fisher–Yates shuffle: every one of the n! orders is equally
+// likely because each Intn call is unbiased.
+func (c cryptoSource) Shuffle(n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, c.Intn(i+1))
+	}
+}
@@ -0,0 +1,82 @@
+package deck
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type NewSourceTest struct {
+	rng         RNG
+	seed        *int64
+	expectedErr error
+}
+
+func TestNewSource(t *testing.T) {
+	tests := []NewSourceTest{
+		{"", nil, nil},
+		{RNGMath, seed(1), nil},
+		{RNGCrypto, nil, nil},
+		{RNGCrypto, seed(1), ErrSeedWithCryptoRNG},
+		{RNGFair, nil, ErrFairReshuffle},
+		{"dice", nil, ErrUnknownRNG{RNG: "dice"}},
+	}
+
+	for _, test := range tests {
+		src, err := NewSource(test.rng, test.seed)
+		if !cmp.Equal(err, test.expectedErr, cmp.Comparer(func(a, b error) bool { return a == b })) {
+			t.Errorf("Failed for rng '%s': expected error %v, got %v", test.rng, test.expectedErr, err)
+		}
+		if err == nil && src == nil {
+			t.Errorf("Failed for rng '%s': expected a source, got nil", test.rng)
+		}
+	}
+
+	src, _ := NewSource(RNGMath, seed(123))
+	if _, ok := src.(*rand.Rand); !ok {
+		t.Errorf("Failed for math rng: expected a *rand.Rand, got %T", src)
+	}
+}
+
+func TestCryptoShuffleIsUniform(t *testing.T) {
+	const trials = 60000
+	src, _ := NewSource(RNGCrypto, nil)
+	counts := make(map[string]int)
+	for i := 0; i < trials; i++ {
+		d := Deck{{Value: Ace, Suit: Spades}, {Value: Two, Suit: Spades}, {Value: Three, Suit: Spades}}
+		d.Shuffle(src)
+		counts[d[0].Code()+d[1].Code()+d[2].Code()]++
+	}
+	if len(counts) != 6 {
+		t.Errorf("Failed for crypto shuffle: expected all 6 orders, got %v", counts)
+	}
+	// Each order is expected 10000 times with a standard deviation of about
+	// 91, so 600 either way would be a 6.5 sigma event for a fair shuffle.
+	for order, count := range counts {
+		if count < trials/6-600 || count > trials/6+600 {
+			t.Errorf("Failed for crypto shuffle: order %s came up %d times out of %d", order, count, trials)
+		}
+	}
+}
+
+func TestNewDeckCryptoRNG(t *testing.T) {
+	d, err := New(&NewDeckOpts{Shuffle: true, RNG: RNGCrypto, Decks: 2})
+	if err != nil {
+		t.Errorf("Failed for crypto shuffled deck: expected error %v, got %v", nil, err)
+	}
+	counts := make(map[Card]int)
+	for _, card := range d {
+		counts[card]++
+	}
+	for _, card := range getDefaultDeck() {
+		if counts[card] != 2 {
+			t.Errorf("Failed for crypto shuffled deck: expected %d copies of %v, got %d", 2, card, counts[card])
+		}
+	}
+
+	_, err = New(&NewDeckOpts{Shuffle: true, RNG: RNGCrypto, Seed: seed(1)})
+	if err != ErrSeedWithCryptoRNG {
+		t.Errorf("Failed for crypto rng with seed: expected error %v, got %v", ErrSeedWithCryptoRNG, err)
+	}
+}
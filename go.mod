@@ -7,5 +7,6 @@ require (
 	github.com/google/uuid v1.3.0
 	github.com/joho/godotenv v1.4.0
 	github.com/julienschmidt/httprouter v1.3.0
+	go.etcd.io/bbolt v1.3.6
 	go.mongodb.org/mongo-driver v1.8.1
 )
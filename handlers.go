@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/AbhilashJN/cards/api"
-	"github.com/AbhilashJN/cards/database"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -16,12 +15,8 @@ type genericResponseMessage struct {
 	Message string `json:"message"`
 }
 
-func (s *server) handleCreateDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	collection := s.dbClient.Collection("decks")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	dc := &database.DeckCRUDOperator{Collection: collection}
-	responseBody, responseCode, err := api.HandleCreateDeck(r, ps, dc, ctx)
+// writeResponse sends responseBody, or err if it is not nil, as JSON.
+func writeResponse(w http.ResponseWriter, r *http.Request, responseBody interface{}, responseCode int, err error) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(responseCode)
 	e := json.NewEncoder(w)
@@ -31,39 +26,156 @@ func (s *server) handleCreateDeck(w http.ResponseWriter, r *http.Request, ps htt
 		e.Encode(responseBody)
 	}
 	log.Println(r.Method, r.URL.Path, responseCode)
+}
 
+func (s *server) handleCreateDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleCreateDeck(r, ps, s.decks, ctx)
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleListDecks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = api.WithPrivilege(ctx, api.HasAdminToken(r, s.adminToken))
+	responseBody, responseCode, err := api.HandleListDecks(r, ps, s.decks, ctx)
+	writeResponse(w, r, responseBody, responseCode, err)
 }
 
 func (s *server) handleGetDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	collection := s.dbClient.Collection("decks")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
-	dc := &database.DeckCRUDOperator{Collection: collection}
-	responseBody, responseCode, err := api.HandleGetDeck(r, ps, dc, ctx)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(responseCode)
-	e := json.NewEncoder(w)
-	if err != nil {
-		e.Encode(genericResponseMessage{err.Error()})
-	} else {
-		e.Encode(responseBody)
+	ctx = api.WithPrivilege(ctx, api.HasAdminToken(r, s.adminToken))
+	responseBody, responseCode, err := api.HandleGetDeck(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
 	}
-	log.Println(r.Method, r.URL.Path, responseCode)
+	writeResponse(w, r, responseBody, responseCode, err)
 }
 
 func (s *server) handleDrawCards(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	collection := s.dbClient.Collection("decks")
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
-	dc := &database.DeckCRUDOperator{Collection: collection}
-	responseBody, responseCode, err := api.HandleDrawCards(r, ps, dc, ctx)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(responseCode)
-	e := json.NewEncoder(w)
-	if err != nil {
-		e.Encode(genericResponseMessage{err.Error()})
-	} else {
-		e.Encode(responseBody)
+	responseBody, responseCode, err := api.HandleDrawCards(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
 	}
-	log.Println(r.Method, r.URL.Path, responseCode)
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleAddToPile(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleAddToPile(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleGetPile(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = api.WithPrivilege(ctx, api.HasAdminToken(r, s.adminToken))
+	responseBody, responseCode, err := api.HandleGetPile(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleDrawFromPile(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleDrawFromPile(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleReturnCards(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleReturnCards(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleShuffleDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleShuffleDeck(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleRevealDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleRevealDeck(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handlePeekCards(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = api.WithPrivilege(ctx, api.HasAdminToken(r, s.adminToken))
+	responseBody, responseCode, err := api.HandlePeekCards(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleSeedDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleSeedDeck(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleCloseDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleCloseDeck(r, ps, s.decks, ctx)
+	if err == nil {
+		w.Header().Set("ETag", api.ETag(responseBody.Version))
+	}
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleDeleteDeck(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleDeleteDeck(r, ps, s.decks, ctx)
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+func (s *server) handleEvaluateHand(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = api.WithPrivilege(ctx, api.HasAdminToken(r, s.adminToken))
+	responseBody, responseCode, err := api.HandleEvaluateHand(r, ps, s.decks, ctx)
+	writeResponse(w, r, responseBody, responseCode, err)
+}
+
+// handleEquity derives its deadline from the request's context, so the
+// calculation also stops if the client goes away.
+func (s *server) handleEquity(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	responseBody, responseCode, err := api.HandleEquity(r, ps, s.decks, ctx)
+	writeResponse(w, r, responseBody, responseCode, err)
 }
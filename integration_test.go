@@ -6,7 +6,6 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -21,32 +20,45 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/julienschmidt/httprouter"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func TestCreateDeckIntegration(t *testing.T) {
+// testAdminToken is the admin token of the integration test server.
+const testAdminToken = "secret"
+
+// newIntegrationTestServer builds a server backed by the deck store selected
+// in test.env, or an in-memory store when DB_DRIVER is not set. The returned
+// function removes everything the test stored.
+func newIntegrationTestServer() (*server, func()) {
 	err := godotenv.Load("test.env")
+	if err != nil && os.Getenv("DB_DRIVER") == "" {
+		os.Setenv("DB_DRIVER", "memory")
+	}
+	decks, closeDB, err := openDeckStore()
 	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
+		log.Fatal(err)
+	}
 	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
+		router:     httprouter.New(),
+		decks:      decks,
+		adminToken: testAdminToken,
 	}
 	s.initRouter()
 
+	cleanup := func() {
+		if dc, ok := decks.(*database.DeckCRUDOperator); ok {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+			dc.Collection.(*mongo.Collection).Drop(ctx)
+		}
+		closeDB()
+	}
+	return s, cleanup
+}
+
+func TestCreateDeckIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+
 	mockBody, _ := json.Marshal(api.CreateDeckRequestBody{Shuffle: false, CustomDeck: false, WantedCards: []string{}})
 	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
 	response := httptest.NewRecorder()
@@ -66,32 +78,11 @@ func TestCreateDeckIntegration(t *testing.T) {
 	if respBody.Remaining != 52 {
 		t.Errorf("Failed create default deck integration test: expected deck size to be %d, got %d", 52, respBody.Remaining)
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
-
 }
 
 func TestCreateDeckIntegrationErrorCase(t *testing.T) {
-	err := godotenv.Load("test.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
-	}
-	s.initRouter()
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
 
 	mockBody, _ := json.Marshal(api.CreateDeckRequestBody{Shuffle: false, CustomDeck: true, WantedCards: []string{}})
 	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
@@ -106,31 +97,13 @@ func TestCreateDeckIntegrationErrorCase(t *testing.T) {
 	if respBody.Message != expectedMessage {
 		t.Errorf("Failed create default deck integration test error case: expected response message %s, got %s", expectedMessage, respBody.Message)
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
 }
 
 func TestGetDeckIntegration(t *testing.T) {
-	err := godotenv.Load("test.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
-	}
-	s.initRouter()
 
 	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
 	deckItem := database.DeckModel{
@@ -138,9 +111,10 @@ func TestGetDeckIntegration(t *testing.T) {
 		Shuffled: false,
 		Cards:    mockDeck,
 	}
-	s.dbClient.Collection("decks").InsertOne(ctx, deckItem)
+	s.decks.InsertDeck(ctx, deckItem)
 
-	req := httptest.NewRequest("GET", "/deck/test-uuid-12345", bytes.NewReader([]byte{}))
+	req := httptest.NewRequest("GET", "/deck/test-uuid-12345?reveal=true", bytes.NewReader([]byte{}))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
 	response := httptest.NewRecorder()
 	s.ServeHTTP(response, req)
 	var respBody api.GetDeckResponseBody
@@ -161,31 +135,13 @@ func TestGetDeckIntegration(t *testing.T) {
 	if len(respBody.Cards) != 52 {
 		t.Errorf("Failed get deck integration test: expected %d cards, got %d", 52, len(respBody.Cards))
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
 }
 
 func TestGetDeckIntegrationErrorCase(t *testing.T) {
-	err := godotenv.Load("test.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
-	}
-	s.initRouter()
 
 	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
 	deckItem := database.DeckModel{
@@ -193,7 +149,7 @@ func TestGetDeckIntegrationErrorCase(t *testing.T) {
 		Shuffled: false,
 		Cards:    mockDeck,
 	}
-	s.dbClient.Collection("decks").InsertOne(ctx, deckItem)
+	s.decks.InsertDeck(ctx, deckItem)
 
 	req := httptest.NewRequest("GET", "/deck/test-uuid-9876", bytes.NewReader([]byte{}))
 	response := httptest.NewRecorder()
@@ -208,31 +164,13 @@ func TestGetDeckIntegrationErrorCase(t *testing.T) {
 		t.Errorf("Failed get deck integration test error case: expected response message %s, got %s", expectedMessage, respBody.Message)
 
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
 }
 
 func TestDrawCardsIntegration(t *testing.T) {
-	err := godotenv.Load("test.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
-	}
-	s.initRouter()
 
 	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
 	deckItem := database.DeckModel{
@@ -240,7 +178,7 @@ func TestDrawCardsIntegration(t *testing.T) {
 		Shuffled: false,
 		Cards:    mockDeck,
 	}
-	s.dbClient.Collection("decks").InsertOne(ctx, deckItem)
+	s.decks.InsertDeck(ctx, deckItem)
 	mockBody, _ := json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-12345", bytes.NewReader(mockBody))
 	response := httptest.NewRecorder()
@@ -259,31 +197,13 @@ func TestDrawCardsIntegration(t *testing.T) {
 	if !cmp.Equal(respBody.Cards, expectedCards) {
 		t.Errorf("Failed draw cards integration test error case: expected cards %v, got %v", expectedCards, respBody.Cards)
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
 }
 
 func TestDrawCardsIntegrationErrorCase(t *testing.T) {
-	err := godotenv.Load("test.env")
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	dbProtocol := os.Getenv("DB_PROTOCOL")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
-	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
-	client, _ := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
-	client.Connect(ctx)
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
-	}
-	s.initRouter()
 
 	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
 	deckItem := database.DeckModel{
@@ -291,7 +211,7 @@ func TestDrawCardsIntegrationErrorCase(t *testing.T) {
 		Shuffled: false,
 		Cards:    mockDeck,
 	}
-	s.dbClient.Collection("decks").InsertOne(ctx, deckItem)
+	s.decks.InsertDeck(ctx, deckItem)
 	mockBody, _ := json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 2})
 	req := httptest.NewRequest("PATCH", "/deck/test-uuid-87654", bytes.NewReader(mockBody))
 	response := httptest.NewRecorder()
@@ -306,5 +226,360 @@ func TestDrawCardsIntegrationErrorCase(t *testing.T) {
 		t.Errorf("Failed get deck integration test error case: expected response message %s, got %s", expectedMessage, respBody.Message)
 
 	}
-	s.dbClient.Collection("decks").Drop(ctx)
+}
+
+func TestDrawCardsIntegrationIfMatch(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	deckItem := database.DeckModel{
+		UUID:    "test-uuid-12345",
+		Cards:   mockDeck,
+		Version: 1,
+	}
+	s.decks.InsertDeck(ctx, deckItem)
+
+	req := httptest.NewRequest("GET", "/deck/test-uuid-12345", bytes.NewReader([]byte{}))
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	etag := response.Header().Get("ETag")
+
+	mockBody, _ := json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 2})
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-12345", bytes.NewReader(mockBody))
+	req.Header.Set("If-Match", etag)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed draw cards if-match integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if newEtag := response.Header().Get("ETag"); newEtag == etag {
+		t.Errorf("Failed draw cards if-match integration test: expected a new etag, got %s", newEtag)
+	}
+
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-12345", bytes.NewReader(mockBody))
+	req.Header.Set("If-Match", etag)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusPreconditionFailed {
+		t.Errorf("Failed draw cards if-match integration test: expected response code %d, got %d", http.StatusPreconditionFailed, response.Code)
+	}
+}
+
+func TestPileIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	deckItem := database.DeckModel{
+		UUID:    "test-uuid-12345",
+		Cards:   mockDeck,
+		Version: 1,
+	}
+	s.decks.InsertDeck(ctx, deckItem)
+
+	mockBody, _ := json.Marshal(api.AddToPileRequestBody{Cards: []string{"KH", "0S"}})
+	req := httptest.NewRequest("POST", "/deck/test-uuid-12345/pile/player1", bytes.NewReader(mockBody))
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var addRespBody api.AddToPileResponseBody
+	json.NewDecoder(response.Body).Decode(&addRespBody)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed pile integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if addRespBody.Remaining != 50 || addRespBody.PileRemaining != 2 {
+		t.Errorf("Failed pile integration test: expected 50 cards in deck and 2 in pile, got %d and %d", addRespBody.Remaining, addRespBody.PileRemaining)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345/pile/player1?reveal=true", bytes.NewReader([]byte{}))
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var getRespBody api.GetPileResponseBody
+	json.NewDecoder(response.Body).Decode(&getRespBody)
+	expectedCards := deck.Deck{
+		{Value: deck.King, Suit: deck.Hearts},
+		{Value: deck.Ten, Suit: deck.Spades},
+	}.ToDeckJSON()
+	if !cmp.Equal(getRespBody.Cards, expectedCards) {
+		t.Errorf("Failed pile integration test: expected pile cards %v, got %v", expectedCards, getRespBody.Cards)
+	}
+
+	mockBody, _ = json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 2})
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-12345/pile/player1", bytes.NewReader(mockBody))
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var drawRespBody api.DrawCardsResponseBody
+	json.NewDecoder(response.Body).Decode(&drawRespBody)
+	if !cmp.Equal(drawRespBody.Cards, expectedCards) {
+		t.Errorf("Failed pile integration test: expected drawn cards %v, got %v", expectedCards, drawRespBody.Cards)
+	}
+}
+
+func TestPeekCardsIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	s.decks.InsertDeck(ctx, database.DeckModel{UUID: "test-uuid-12345", Cards: mockDeck, Version: 1})
+
+	req := httptest.NewRequest("GET", "/deck/test-uuid-12345/peek?count=2", nil)
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("Failed peek integration test without token: expected response code %d, got %d", http.StatusForbidden, response.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345/peek?count=2", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var respBody api.PeekCardsResponseBody
+	json.NewDecoder(response.Body).Decode(&respBody)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed peek integration test with token: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if !cmp.Equal(respBody.Cards, mockDeck[:2].ToDeckJSON()) || respBody.Remaining != 52 {
+		t.Errorf("Failed peek integration test with token: expected top 2 of 52 cards, got %v of %d", respBody.Cards, respBody.Remaining)
+	}
+
+	s.adminToken = ""
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345/peek?count=2", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("Failed peek integration test without a configured token: expected response code %d, got %d", http.StatusForbidden, response.Code)
+	}
+}
+
+func TestGetDeckIntegrationHidesCards(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	s.decks.InsertDeck(ctx, database.DeckModel{UUID: "test-uuid-12345", Cards: mockDeck, Version: 1})
+
+	req := httptest.NewRequest("GET", "/deck/test-uuid-12345", nil)
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var respBody map[string]interface{}
+	json.NewDecoder(response.Body).Decode(&respBody)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed hidden get deck integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if _, ok := respBody["cards"]; ok {
+		t.Errorf("Failed hidden get deck integration test: expected no cards, got %v", respBody["cards"])
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345?reveal=true", nil)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("Failed hidden get deck integration test: expected reveal without token to give %d, got %d", http.StatusForbidden, response.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345?reveal=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var revealed api.GetDeckResponseBody
+	json.NewDecoder(response.Body).Decode(&revealed)
+	if response.Code != http.StatusOK || len(revealed.Cards) != 52 {
+		t.Errorf("Failed hidden get deck integration test: expected reveal with token to give %d and 52 cards, got %d and %d", http.StatusOK, response.Code, len(revealed.Cards))
+	}
+}
+
+func TestCreateThenGetDeckIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+
+	mockBody, _ := json.Marshal(api.CreateDeckRequestBody{Shuffle: true, IncludeJokers: true})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var createRespBody api.CreateDeckResponseBody
+	json.NewDecoder(response.Body).Decode(&createRespBody)
+
+	req = httptest.NewRequest("GET", "/deck/"+createRespBody.DeckId, nil)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var respBody api.GetDeckResponseBody
+	json.NewDecoder(response.Body).Decode(&respBody)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed create then get deck integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if !respBody.Shuffled || respBody.ShuffleMethod != "random" || respBody.RNG != "math" {
+		t.Errorf("Failed create then get deck integration test: expected a deck shuffled at random with math, got %+v", respBody)
+	}
+	if !respBody.IncludeJokers || respBody.OriginalSize != 54 || respBody.CreatedAt.IsZero() {
+		t.Errorf("Failed create then get deck integration test: expected 54 cards with jokers and a creation time, got %+v", respBody)
+	}
+}
+
+func TestCloseAndDeleteDeckIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	s.decks.InsertDeck(ctx, database.DeckModel{UUID: "test-uuid-12345", Cards: mockDeck, Version: 1})
+
+	req := httptest.NewRequest("POST", "/deck/test-uuid-12345/close", nil)
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed close deck integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+
+	mockBody, _ := json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 2})
+	req = httptest.NewRequest("PATCH", "/deck/test-uuid-12345", bytes.NewReader(mockBody))
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusGone {
+		t.Errorf("Failed close deck integration test: expected draw to give %d, got %d", http.StatusGone, response.Code)
+	}
+
+	req = httptest.NewRequest("DELETE", "/deck/test-uuid-12345", nil)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed delete deck integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/deck/test-uuid-12345", nil)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusNotFound {
+		t.Errorf("Failed delete deck integration test: expected get after delete to give %d, got %d", http.StatusNotFound, response.Code)
+	}
+}
+
+func TestListDecksIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+
+	for _, owner := range []string{"alice", "bob", "alice"} {
+		mockBody, _ := json.Marshal(api.CreateDeckRequestBody{Owner: owner, Tags: []string{"poker"}})
+		req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	req := httptest.NewRequest("GET", "/deck?owner=alice&tag=poker&limit=1", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var page api.ListDecksResponseBody
+	json.NewDecoder(response.Body).Decode(&page)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed list decks integration test: expected response code %d, got %d", http.StatusOK, response.Code)
+	}
+	if len(page.Decks) != 1 || page.Decks[0].Owner != "alice" || page.NextCursor == "" {
+		t.Errorf("Failed list decks integration test: expected one deck of alice and a next cursor, got %+v", page)
+	}
+
+	req = httptest.NewRequest("GET", "/deck?owner=alice&tag=poker&limit=1&cursor="+page.NextCursor, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var nextPage api.ListDecksResponseBody
+	json.NewDecoder(response.Body).Decode(&nextPage)
+	if len(nextPage.Decks) != 1 || nextPage.Decks[0].DeckId == page.Decks[0].DeckId || nextPage.NextCursor != "" {
+		t.Errorf("Failed list decks integration test: expected the other deck of alice and no next cursor, got %+v", nextPage)
+	}
+}
+
+func TestRevealIntegrationWithoutAdminToken(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+	s.adminToken = ""
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	mockDeck, _ := deck.New(&deck.NewDeckOpts{Shuffle: false, CustomDeck: false})
+	s.decks.InsertDeck(ctx, database.DeckModel{
+		UUID:    "test-uuid-12345",
+		Cards:   mockDeck[2:],
+		Piles:   map[string]deck.Deck{"player1": mockDeck[:2]},
+		Version: 1,
+	})
+
+	for _, path := range []string{"/deck/test-uuid-12345?reveal=true", "/deck/test-uuid-12345/pile/player1?reveal=true"} {
+		for _, header := range []string{"", "Bearer "} {
+			req := httptest.NewRequest("GET", path, nil)
+			if header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			response := httptest.NewRecorder()
+			s.ServeHTTP(response, req)
+			if response.Code != http.StatusForbidden {
+				t.Errorf("Failed reveal integration test without a configured token for %s with header %q: expected response code %d, got %d", path, header, http.StatusForbidden, response.Code)
+			}
+		}
+	}
+}
+
+func TestFairDeckIntegration(t *testing.T) {
+	s, cleanup := newIntegrationTestServer()
+	defer cleanup()
+
+	mockBody, _ := json.Marshal(api.CreateDeckRequestBody{RNG: "fair"})
+	req := httptest.NewRequest("POST", "/deck", bytes.NewReader(mockBody))
+	response := httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var created api.CreateDeckResponseBody
+	json.NewDecoder(response.Body).Decode(&created)
+	if response.Code != http.StatusCreated || created.ServerSeedHash == "" || created.Shuffled {
+		t.Fatalf("Failed fair deck integration test: expected an unshuffled deck with a server seed hash, got %d and %+v", response.Code, created)
+	}
+
+	mockBody, _ = json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 52})
+	req = httptest.NewRequest("PATCH", "/deck/"+created.DeckId, bytes.NewReader(mockBody))
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusConflict {
+		t.Errorf("Failed fair deck integration test: expected draw before seeding to give %d, got %d", http.StatusConflict, response.Code)
+	}
+
+	mockBody, _ = json.Marshal(api.SeedDeckRequestBody{ClientSeed: "player-one"})
+	req = httptest.NewRequest("POST", "/deck/"+created.DeckId+"/seed", bytes.NewReader(mockBody))
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	if response.Code != http.StatusOK {
+		t.Errorf("Failed fair deck integration test: expected seeding to give %d, got %d", http.StatusOK, response.Code)
+	}
+
+	mockBody, _ = json.Marshal(api.DrawCardsRequestBody{NumberOfCards: 52})
+	req = httptest.NewRequest("PATCH", "/deck/"+created.DeckId, bytes.NewReader(mockBody))
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var drawn api.DrawCardsResponseBody
+	json.NewDecoder(response.Body).Decode(&drawn)
+
+	req = httptest.NewRequest("GET", "/deck/"+created.DeckId+"/reveal", nil)
+	response = httptest.NewRecorder()
+	s.ServeHTTP(response, req)
+	var revealed api.RevealDeckResponseBody
+	json.NewDecoder(response.Body).Decode(&revealed)
+	if response.Code != http.StatusOK || revealed.ServerSeedHash != created.ServerSeedHash || revealed.ClientSeed != "player-one" {
+		t.Fatalf("Failed fair deck integration test: expected the reveal to match the commitment, got %d and %+v", response.Code, revealed)
+	}
+	if !cmp.Equal(revealed.Shuffled, drawn.Cards) {
+		t.Errorf("Failed fair deck integration test: expected the revealed order to be the order drawn")
+	}
+	unshuffled, _ := deck.New(&deck.NewDeckOpts{})
+	shuffled := append(deck.Deck{}, unshuffled...)
+	shuffled.Shuffle(deck.NewFairSource(revealed.ServerSeed, revealed.ClientSeed))
+	if !cmp.Equal(shuffled.ToDeckJSON(), drawn.Cards) || deck.Commitment(revealed.ServerSeed) != created.ServerSeedHash {
+		t.Errorf("Failed fair deck integration test: expected the drawn order to be reproducible from the revealed seeds")
+	}
 }
@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/AbhilashJN/cards/database"
 	"github.com/joho/godotenv"
 	"github.com/julienschmidt/httprouter"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -19,6 +20,81 @@ func main() {
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
+	decks, closeDB, err := openDeckStore()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeDB()
+	decks, stopExpiry, err := configureExpiry(decks)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer stopExpiry()
+
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("ADMIN_TOKEN is not set, privileged operations are disabled")
+	}
+	s := &server{
+		router:     httprouter.New(),
+		decks:      decks,
+		adminToken: adminToken,
+	}
+	s.initRouter()
+	log.Fatal(http.ListenAndServe(":8080", s))
+}
+
+// openDeckStore connects to the deck store selected by DB_DRIVER and returns
+// it along with a function that releases it.
+func openDeckStore() (database.DeckCRUDer, func(), error) {
+	switch dbDriver := os.Getenv("DB_DRIVER"); dbDriver {
+	case "", "mongo":
+		return openMongoDeckStore()
+	case "memory":
+		return database.NewMemoryDeckStore(), func() {}, nil
+	case "bolt":
+		store, err := database.OpenBoltDeckStore(os.Getenv("DB_PATH"))
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown DB_DRIVER %q", dbDriver)
+	}
+}
+
+// configureExpiry expires decks that have been idle for longer than
+// DECK_TTL, if it is set. MongoDB deletes them with a TTL index, the other
+// stores with a background sweeper that the returned function stops.
+func configureExpiry(decks database.DeckCRUDer) (database.DeckCRUDer, func(), error) {
+	ttlSetting := os.Getenv("DECK_TTL")
+	if ttlSetting == "" {
+		return decks, func() {}, nil
+	}
+	ttl, err := time.ParseDuration(ttlSetting)
+	if err != nil || ttl <= 0 || ttl > database.MaxTTL {
+		return nil, nil, fmt.Errorf("DECK_TTL must be a positive duration such as 24h, at most %s, got %q", database.MaxTTL, ttlSetting)
+	}
+	expiring := database.NewExpiringDeckStore(decks, ttl)
+
+	switch store := decks.(type) {
+	case *database.DeckCRUDOperator:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := store.EnsureExpiryIndex(ctx, expiring.PurgeAfter()); err != nil {
+			return nil, nil, err
+		}
+		return expiring, func() {}, nil
+	case database.IdleDeckPurger:
+		ctx, cancel := context.WithCancel(context.Background())
+		go expiring.RunSweeper(ctx, store)
+		return expiring, cancel, nil
+	default:
+		return expiring, func() {}, nil
+	}
+}
+
+func openMongoDeckStore() (database.DeckCRUDer, func(), error) {
 	dbProtocol := os.Getenv("DB_PROTOCOL")
 	dbHost := os.Getenv("DB_HOST")
 	dbPort := os.Getenv("DB_PORT")
@@ -26,21 +102,20 @@ func main() {
 	dbConnectionString := fmt.Sprintf("%s://%s:%s", dbProtocol, dbHost, dbPort)
 	client, err := mongo.NewClient(options.Client().ApplyURI(dbConnectionString))
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	err = client.Connect(ctx)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
-	defer client.Disconnect(ctx)
-
-	dbClient := client.Database(dbName)
-	s := &server{
-		router:   httprouter.New(),
-		dbClient: dbClient,
+	closeFn := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		client.Disconnect(ctx)
 	}
-	s.initRouter()
-	log.Fatal(http.ListenAndServe(":8080", s))
+
+	collection := client.Database(dbName).Collection("decks")
+	return &database.DeckCRUDOperator{Collection: collection}, closeFn, nil
 }
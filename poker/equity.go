@@ -0,0 +1,273 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/AbhilashJN/cards/deck"
+)
+
+const (
+	// HoleCards is the number of cards dealt to each Texas Hold'em player.
+	HoleCards = 2
+	// BoardSize is the number of community cards of a complete board.
+	BoardSize = 5
+	// MinPlayers and MaxPlayers bound the number of players whose equity
+	// can be calculated.
+	MinPlayers = 2
+	MaxPlayers = 10
+)
+
+// checkEvery is how many deals are played between checks of the context.
+const checkEvery = 1024
+
+var ErrNoIterations = errors.New("Equity needs at least one iteration")
+
+type ErrPlayerCount struct {
+	Players int
+}
+
+func (e ErrPlayerCount) Error() string {
+	return fmt.Sprintf("Equity needs between %d and %d players, got %d", MinPlayers, MaxPlayers, e.Players)
+}
+
+type ErrHoleCards struct {
+	// Player is the index of the player, from 0.
+	Player int
+	Size   int
+}
+
+func (e ErrHoleCards) Error() string {
+	return fmt.Sprintf("Player %d must have %d hole cards, got %d", e.Player+1, HoleCards, e.Size)
+}
+
+type ErrBoardSize struct {
+	Size int
+}
+
+func (e ErrBoardSize) Error() string {
+	return fmt.Sprintf("The board can have at most %d cards, got %d", BoardSize, e.Size)
+}
+
+type EquityOpts struct {
+	// Hands are the hole cards of each player.
+	Hands []deck.Deck
+	// Board holds the community cards dealt so far, if any.
+	Board deck.Deck
+	// Iterations caps the number of boards played out. If every way to
+	// complete the board fits within it, they are all enumerated; otherwise
+	// Iterations boards are sampled at random.
+	Iterations int
+	// Source draws the sampled boards. If nil, an unpredictable math/rand
+	// source is used.
+	Source deck.Source
+}
+
+// PlayerEquity holds how a player's hand fared over the boards played out,
+// as fractions of them.
+type PlayerEquity struct {
+	// Win is the fraction of boards on which the player alone had the best
+	// hand, and Tie the fraction on which they shared it.
+	Win float64
+	Tie float64
+	// Equity is the share of the pot the player wins on average: a win
+	// counts as one and a tie between n players as 1/n.
+	Equity float64
+}
+
+type Equity struct {
+	// Players are in the order of EquityOpts.Hands.
+	Players []PlayerEquity
+	// Boards is the number of boards played out.
+	Boards int
+	// Exact is true if every way to complete the board was played out, so
+	// the results are exact rather than estimates.
+	Exact bool
+}
+
+// equityCounter plays boards out and tallies the results.
+type equityCounter struct {
+	// hands hold each player's hole cards followed by the board.
+	hands  []deck.Deck
+	scores []Score
+	wins   []int
+	ties   []int
+	shares []float64
+	boards int
+}
+
+func newEquityCounter(holeCards []deck.Deck, board deck.Deck) *equityCounter {
+	c := &equityCounter{
+		hands:  make([]deck.Deck, len(holeCards)),
+		scores: make([]Score, len(holeCards)),
+		wins:   make([]int, len(holeCards)),
+		ties:   make([]int, len(holeCards)),
+		shares: make([]float64, len(holeCards)),
+	}
+	for i, hole := range holeCards {
+		c.hands[i] = make(deck.Deck, 0, HoleCards+BoardSize)
+		c.hands[i] = append(append(c.hands[i], hole...), board...)
+	}
+	return c
+}
+
+// play scores every hand once the board is completed with rest.
+func (c *equityCounter) play(rest deck.Deck) {
+	best, winners := Score(0), 0
+	for i, hand := range c.hands {
+		// hand has room for the rest of the board, so this does not
+		// allocate, and the next play overwrites it.
+		s := ScoreCards(append(hand, rest...))
+		c.scores[i] = s
+		switch {
+		case s > best:
+			best, winners = s, 1
+		case s == best:
+			winners++
+		}
+	}
+	for i, s := range c.scores {
+		switch {
+		case s != best:
+		case winners == 1:
+			c.wins[i]++
+			c.shares[i]++
+		default:
+			c.ties[i]++
+			c.shares[i] += 1 / float64(winners)
+		}
+	}
+	c.boards++
+}
+
+func (c *equityCounter) equity(exact bool) Equity {
+	result := Equity{Players: make([]PlayerEquity, len(c.hands)), Boards: c.boards, Exact: exact}
+	for i := range c.hands {
+		result.Players[i] = PlayerEquity{
+			Win:    float64(c.wins[i]) / float64(c.boards),
+			Tie:    float64(c.ties[i]) / float64(c.boards),
+			Equity: c.shares[i] / float64(c.boards),
+		}
+	}
+	return result
+}
+
+// CalculateEquity plays out the Texas Hold'em boards that complete
+// opts.Board and returns how often each hand wins and ties. Every board is
+// played out if there are at most opts.Iterations of them, otherwise that
+// many are sampled at random.
+//
+// Sampling stops early once ctx is done, and the boards sampled until then
+// are the result. Enumeration visits boards in order, so stopping it early
+// would skew the result: it fails with the context's error instead.
+func CalculateEquity(ctx context.Context, opts EquityOpts) (Equity, error) {
+	if len(opts.Hands) < MinPlayers || len(opts.Hands) > MaxPlayers {
+		return Equity{}, ErrPlayerCount{Players: len(opts.Hands)}
+	}
+	if len(opts.Board) > BoardSize {
+		return Equity{}, ErrBoardSize{Size: len(opts.Board)}
+	}
+	if opts.Iterations < 1 {
+		return Equity{}, ErrNoIterations
+	}
+	seen := make(map[deck.Card]bool)
+	for i, hole := range opts.Hands {
+		if len(hole) != HoleCards {
+			return Equity{}, ErrHoleCards{Player: i, Size: len(hole)}
+		}
+		for _, c := range hole {
+			if err := checkCard(c, seen); err != nil {
+				return Equity{}, err
+			}
+		}
+	}
+	for _, c := range opts.Board {
+		if err := checkCard(c, seen); err != nil {
+			return Equity{}, err
+		}
+	}
+
+	full, err := deck.New(&deck.NewDeckOpts{})
+	if err != nil {
+		return Equity{}, err
+	}
+	stub := make(deck.Deck, 0, len(full)-len(seen))
+	for _, c := range full {
+		if !seen[c] {
+			stub = append(stub, c)
+		}
+	}
+	missing := BoardSize - len(opts.Board)
+	counter := newEquityCounter(opts.Hands, opts.Board)
+
+	if boards := combinations(len(stub), missing); boards <= opts.Iterations {
+		if err := enumerateBoards(ctx, counter, stub, missing); err != nil {
+			return Equity{}, err
+		}
+		return counter.equity(true), nil
+	}
+
+	src := opts.Source
+	if src == nil {
+		if src, err = deck.NewSource(deck.RNGMath, nil); err != nil {
+			return Equity{}, err
+		}
+	}
+	for i := 0; i < opts.Iterations; i++ {
+		if i%checkEvery == 0 && ctx.Err() != nil {
+			break
+		}
+		// A partial Fisher–Yates shuffle moves a random board to the front
+		// of the stub.
+		for j := 0; j < missing; j++ {
+			k := j + src.Intn(len(stub)-j)
+			stub[j], stub[k] = stub[k], stub[j]
+		}
+		counter.play(stub[:missing])
+	}
+	if counter.boards == 0 {
+		return Equity{}, ctx.Err()
+	}
+	return counter.equity(false), nil
+}
+
+// enumerateBoards plays out every set of n cards of stub.
+func enumerateBoards(ctx context.Context, counter *equityCounter, stub deck.Deck, n int) error {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	rest := make(deck.Deck, n)
+	for {
+		if counter.boards%checkEvery == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		for i, index := range indices {
+			rest[i] = stub[index]
+		}
+		counter.play(rest)
+
+		// Move on to the next combination in lexicographic order.
+		i := n - 1
+		for i >= 0 && indices[i] == len(stub)-n+i {
+			i--
+		}
+		if i < 0 {
+			return nil
+		}
+		indices[i]++
+		for j := i + 1; j < n; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+	}
+}
+
+// combinations returns the number of ways to choose k of n items.
+func combinations(n, k int) int {
+	result := 1
+	for i := 0; i < k; i++ {
+		result = result * (n - i) / (i + 1)
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package poker
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCalculateEquity(t *testing.T) {
+	tests := []struct {
+		name           string
+		hands          [][]string
+		board          []string
+		iterations     int
+		expectedBoards int
+		expectedExact  bool
+		expected       []PlayerEquity
+		// tolerance is how far sampled results may be from expected.
+		tolerance float64
+	}{
+		{
+			name:           "complete board",
+			hands:          [][]string{{"AS", "AH"}, {"KS", "KH"}},
+			board:          []string{"2C", "7D", "9S", "JD", "KC"},
+			iterations:     1,
+			expectedBoards: 1,
+			expectedExact:  true,
+			expected:       []PlayerEquity{{Win: 0, Tie: 0, Equity: 0}, {Win: 1, Tie: 0, Equity: 1}},
+		},
+		{
+			name:           "turn",
+			hands:          [][]string{{"AS", "AH"}, {"KS", "KH"}},
+			board:          []string{"2C", "7D", "9S", "JD"},
+			iterations:     1000,
+			expectedBoards: 44,
+			expectedExact:  true,
+			expected:       []PlayerEquity{{Win: 42.0 / 44, Equity: 42.0 / 44}, {Win: 2.0 / 44, Equity: 2.0 / 44}},
+		},
+		{
+			name:           "board plays",
+			hands:          [][]string{{"2C", "3C"}, {"2D", "3D"}, {"4H", "5H"}},
+			board:          []string{"AS", "KS", "QS", "JS", "0S"},
+			iterations:     1000,
+			expectedBoards: 1,
+			expectedExact:  true,
+			expected:       []PlayerEquity{{Tie: 1, Equity: 1.0 / 3}, {Tie: 1, Equity: 1.0 / 3}, {Tie: 1, Equity: 1.0 / 3}},
+		},
+		{
+			name:           "sampled preflop",
+			hands:          [][]string{{"AS", "AH"}, {"KS", "KH"}},
+			iterations:     20000,
+			expectedBoards: 20000,
+			expectedExact:  false,
+			expected:       []PlayerEquity{{Win: 0.8195, Tie: 0.0046, Equity: 0.8218}, {Win: 0.1759, Tie: 0.0046, Equity: 0.1782}},
+			tolerance:      0.015,
+		},
+	}
+	for _, test := range tests {
+		opts := EquityOpts{Board: cardsOf(t, test.board...), Iterations: test.iterations, Source: rand.New(rand.NewSource(1))}
+		for _, hand := range test.hands {
+			opts.Hands = append(opts.Hands, cardsOf(t, hand...))
+		}
+		result, err := CalculateEquity(context.TODO(), opts)
+		if err != nil {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, nil, err)
+			continue
+		}
+		if result.Boards != test.expectedBoards || result.Exact != test.expectedExact {
+			t.Errorf("Failed for %s case: expected %d boards with exact %v, got %d with exact %v", test.name, test.expectedBoards, test.expectedExact, result.Boards, result.Exact)
+		}
+		approx := cmp.Comparer(func(a, b float64) bool { return math.Abs(a-b) <= test.tolerance+1e-9 })
+		if !cmp.Equal(result.Players, test.expected, approx) {
+			t.Errorf("Failed for %s case: expected players to be %v, got %v", test.name, test.expected, result.Players)
+		}
+	}
+}
+
+func TestCalculateEquityEnumeratesFlop(t *testing.T) {
+	opts := EquityOpts{
+		Hands:      []deck.Deck{cardsOf(t, "AS", "AH"), cardsOf(t, "KS", "KH"), cardsOf(t, "8C", "9C")},
+		Board:      cardsOf(t, "0C", "JC", "2D"),
+		Iterations: 1000,
+	}
+	result, err := CalculateEquity(context.TODO(), opts)
+	if err != nil {
+		t.Fatalf("Expected error to be %v, got %v", nil, err)
+	}
+	if result.Boards != 903 || !result.Exact {
+		t.Errorf("Expected 903 boards with exact true, got %d with exact %v", result.Boards, result.Exact)
+	}
+	total := 0.0
+	for _, player := range result.Players {
+		total += player.Equity
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("Expected equities to add up to 1, got %v", total)
+	}
+}
+
+func TestCalculateEquityCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name       string
+		board      []string
+		iterations int
+	}{
+		{name: "enumerated", board: []string{"2C", "7D", "9S"}, iterations: 1000},
+		{name: "sampled", iterations: 1000},
+	}
+	for _, test := range tests {
+		opts := EquityOpts{
+			Hands:      []deck.Deck{cardsOf(t, "AS", "AH"), cardsOf(t, "KS", "KH")},
+			Board:      cardsOf(t, test.board...),
+			Iterations: test.iterations,
+		}
+		if _, err := CalculateEquity(ctx, opts); err != context.Canceled {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, context.Canceled, err)
+		}
+	}
+}
+
+func TestCalculateEquityErrors(t *testing.T) {
+	aces := cardsOf(t, "AS", "AH")
+	kings := cardsOf(t, "KS", "KH")
+	tests := []struct {
+		name        string
+		opts        EquityOpts
+		expectedErr error
+	}{
+		{name: "one player", opts: EquityOpts{Hands: []deck.Deck{aces}, Iterations: 1}, expectedErr: ErrPlayerCount{Players: 1}},
+		{name: "three hole cards", opts: EquityOpts{Hands: []deck.Deck{aces, cardsOf(t, "KS", "KH", "KD")}, Iterations: 1}, expectedErr: ErrHoleCards{Player: 1, Size: 3}},
+		{name: "long board", opts: EquityOpts{Hands: []deck.Deck{aces, kings}, Board: cardsOf(t, "2C", "3C", "4C", "5C", "6C", "7C"), Iterations: 1}, expectedErr: ErrBoardSize{Size: 6}},
+		{name: "no iterations", opts: EquityOpts{Hands: []deck.Deck{aces, kings}}, expectedErr: ErrNoIterations},
+		{name: "card dealt twice", opts: EquityOpts{Hands: []deck.Deck{aces, kings}, Board: cardsOf(t, "2C", "AH", "4C"), Iterations: 1}, expectedErr: ErrDuplicateCard{Card: deck.Card{Value: deck.Ace, Suit: deck.Hearts}}},
+		{name: "joker", opts: EquityOpts{Hands: []deck.Deck{aces, {deck.RedJoker, deck.BlackJoker}}, Iterations: 1}, expectedErr: ErrUnsupportedCard{Card: deck.RedJoker}},
+	}
+	for _, test := range tests {
+		if _, err := CalculateEquity(context.TODO(), test.opts); err != test.expectedErr {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+func BenchmarkCalculateEquity(b *testing.B) {
+	opts := EquityOpts{
+		Hands:      []deck.Deck{{{Value: deck.Ace, Suit: deck.Spades}, {Value: deck.Ace, Suit: deck.Hearts}}, {{Value: deck.King, Suit: deck.Spades}, {Value: deck.King, Suit: deck.Hearts}}},
+		Iterations: 10000,
+		Source:     rand.New(rand.NewSource(1)),
+	}
+	for i := 0; i < b.N; i++ {
+		if _, err := CalculateEquity(context.TODO(), opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+// Package poker ranks poker hands made of French deck.Cards.
+//
+// Hands are scored with lookup tables indexed by 13 bit masks of card
+// values, so scoring a hand of up to seven cards takes no allocations and
+// does not try every five card combination. This makes it fast enough for
+// Monte Carlo simulations.
+package poker
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/AbhilashJN/cards/deck"
+)
+
+const (
+	// MinHandSize and MaxHandSize bound the number of cards a hand is made
+	// of, such as five for draw poker or seven for Texas Hold'em.
+	MinHandSize = 5
+	MaxHandSize = 7
+	// BestHandSize is the number of cards that make up the best hand.
+	BestHandSize = 5
+)
+
+// Category is the kind of a poker hand. Higher categories beat lower ones.
+type Category uint8
+
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var categoryNames = [...]string{
+	HighCard:      "high card",
+	OnePair:       "one pair",
+	TwoPair:       "two pair",
+	ThreeOfAKind:  "three of a kind",
+	Straight:      "straight",
+	Flush:         "flush",
+	FullHouse:     "full house",
+	FourOfAKind:   "four of a kind",
+	StraightFlush: "straight flush",
+}
+
+func (c Category) String() string {
+	if int(c) >= len(categoryNames) {
+		return fmt.Sprintf("Category(%d)", c)
+	}
+	return categoryNames[c]
+}
+
+// Score orders hands: a hand beats another if and only if its score is
+// higher, and hands of equal score split the pot. The category is stored in
+// the top bits, followed by the ranks of the best five cards in order of
+// significance, four bits each.
+type Score uint32
+
+const categoryShift = 4 * BestHandSize
+
+func (s Score) Category() Category {
+	return Category(s >> categoryShift)
+}
+
+// rank returns the rank of the i-th most significant card of the hand, from
+// 0 for a Two to 12 for an Ace.
+func (s Score) rank(i int) int {
+	return int(s>>(4*(BestHandSize-1-i))) & 0xF
+}
+
+type ErrHandSize struct {
+	Size int
+}
+
+func (e ErrHandSize) Error() string {
+	return fmt.Sprintf("A poker hand must have between %d and %d cards, got %d", MinHandSize, MaxHandSize, e.Size)
+}
+
+type ErrDuplicateCard struct {
+	Card deck.Card
+}
+
+func (e ErrDuplicateCard) Error() string {
+	return fmt.Sprintf("Card %s is dealt more than once", e.Card.Code())
+}
+
+type ErrUnsupportedCard struct {
+	Card deck.Card
+}
+
+func (e ErrUnsupportedCard) Error() string {
+	return fmt.Sprintf("Card %s cannot be part of a poker hand", e.Card.Code())
+}
+
+// Hand describes the best five cards of a hand.
+type Hand struct {
+	Category Category
+	Score    Score
+	// Cards are the best five cards, the ones making up the category first
+	// and then the kickers, each group from the highest rank to the lowest.
+	Cards deck.Deck
+	// Kickers are the cards of Cards that only break ties between hands of
+	// the same category and ranks, such as the three unpaired cards of a
+	// pair. Straights, flushes and full houses have none.
+	Kickers deck.Deck
+}
+
+// rankIndex maps card values to ranks, from 0 for a Two to 12 for an Ace.
+var rankIndex = map[deck.CardValue]int{
+	deck.Two: 0, deck.Three: 1, deck.Four: 2, deck.Five: 3, deck.Six: 4,
+	deck.Seven: 5, deck.Eight: 6, deck.Nine: 7, deck.Ten: 8, deck.Jack: 9,
+	deck.Queen: 10, deck.King: 11, deck.Ace: 12,
+}
+
+// rankMask, indexed by card value, is the bit of the value's rank.
+var rankMask [deck.King + 1]uint16
+
+const (
+	numRanks = 13
+	numMasks = 1 << numRanks
+	// wheelMask is A-2-3-4-5, the lowest straight.
+	wheelMask = 1<<12 | 0xF
+)
+
+var (
+	// topRanks holds the five highest ranks of every mask, packed like the
+	// ranks of a Score.
+	topRanks [numMasks]Score
+	// straightHigh holds the rank of the highest card of the highest
+	// straight in every mask, or -1 if there is none.
+	straightHigh [numMasks]int8
+)
+
+func init() {
+	for value, rank := range rankIndex {
+		rankMask[value] = 1 << rank
+	}
+	for mask := 0; mask < numMasks; mask++ {
+		var packed Score
+		m, n := uint16(mask), 0
+		for ; m != 0 && n < BestHandSize; n++ {
+			top := highestRank(m)
+			packed = packed<<4 | Score(top)
+			m &^= 1 << top
+		}
+		topRanks[mask] = packed << (4 * (BestHandSize - n))
+
+		straightHigh[mask] = -1
+		for high := numRanks - 1; high >= 4; high-- {
+			run := uint16(0x1F) << (high - 4)
+			if uint16(mask)&run == run {
+				straightHigh[mask] = int8(high)
+				break
+			}
+		}
+		if straightHigh[mask] < 0 && mask&wheelMask == wheelMask {
+			straightHigh[mask] = 3
+		}
+	}
+}
+
+func highestRank(mask uint16) int {
+	return bits.Len16(mask) - 1
+}
+
+// top returns the n highest ranks of mask, packed to follow skip more
+// significant ranks.
+func top(mask uint16, n, skip int) Score {
+	return topRanks[mask] >> (4 * (BestHandSize - n)) << (4 * (BestHandSize - skip - n))
+}
+
+func score(category Category, ranks ...int) Score {
+	s := Score(category) << categoryShift
+	for i, r := range ranks {
+		s |= Score(r) << (4 * (BestHandSize - 1 - i))
+	}
+	return s
+}
+
+// ScoreCards scores the best five card hand in cards. It is the fast path
+// for simulations: cards must be between five and seven distinct cards of
+// the French suits without jokers, otherwise the score is meaningless. Use
+// Evaluate to check the cards and to find out which five make the hand.
+func ScoreCards(cards deck.Deck) Score {
+	var suits [4]uint16
+	for _, c := range cards {
+		suits[c.Suit&3] |= rankMask[c.Value]
+	}
+	s0, s1, s2, s3 := suits[0], suits[1], suits[2], suits[3]
+
+	flush := uint16(0)
+	for _, m := range suits {
+		if bits.OnesCount16(m) >= BestHandSize {
+			flush = m
+		}
+	}
+	if flush != 0 && straightHigh[flush] >= 0 {
+		return score(StraightFlush, int(straightHigh[flush]))
+	}
+
+	all := s0 | s1 | s2 | s3
+	twoOrMore := s0&s1 | s0&s2 | s0&s3 | s1&s2 | s1&s3 | s2&s3
+	threeOrMore := s0&s1&s2 | s0&s1&s3 | s0&s2&s3 | s1&s2&s3
+	four := s0 & s1 & s2 & s3
+
+	if four != 0 {
+		q := highestRank(four)
+		return score(FourOfAKind, q) | top(all&^(1<<q), 1, 1)
+	}
+	if threeOrMore != 0 {
+		t := highestRank(threeOrMore)
+		// A second three of a kind counts as the pair of a full house.
+		if pairs := twoOrMore &^ (1 << t); pairs != 0 {
+			return score(FullHouse, t, highestRank(pairs))
+		}
+	}
+	if flush != 0 {
+		return Score(Flush)<<categoryShift | topRanks[flush]
+	}
+	if high := straightHigh[all]; high >= 0 {
+		return score(Straight, int(high))
+	}
+	if threeOrMore != 0 {
+		t := highestRank(threeOrMore)
+		return score(ThreeOfAKind, t) | top(all&^(1<<t), 2, 1)
+	}
+	if twoOrMore != 0 {
+		p := highestRank(twoOrMore)
+		if rest := twoOrMore &^ (1 << p); rest != 0 {
+			p2 := highestRank(rest)
+			return score(TwoPair, p, p2) | top(all&^(1<<p|1<<p2), 1, 2)
+		}
+		return score(OnePair, p) | top(all&^(1<<p), 3, 1)
+	}
+	return Score(HighCard)<<categoryShift | topRanks[all]
+}
+
+// Evaluate finds the best five card hand in cards, which must be between
+// MinHandSize and MaxHandSize distinct cards of the French suits.
+func Evaluate(cards deck.Deck) (Hand, error) {
+	if len(cards) < MinHandSize || len(cards) > MaxHandSize {
+		return Hand{}, ErrHandSize{Size: len(cards)}
+	}
+	seen := make(map[deck.Card]bool, len(cards))
+	for _, c := range cards {
+		if err := checkCard(c, seen); err != nil {
+			return Hand{}, err
+		}
+	}
+
+	s := ScoreCards(cards)
+	hand := Hand{Category: s.Category(), Score: s}
+	pool := append(deck.Deck{}, cards...)
+	if hand.Category == Flush || hand.Category == StraightFlush {
+		pool = flushCards(pool)
+	}
+	take := func(rank, n int) deck.Deck {
+		var taken deck.Deck
+		for i := 0; i < len(pool) && len(taken) < n; {
+			if rankIndex[pool[i].Value] == rank {
+				taken = append(taken, pool[i])
+				pool = append(pool[:i], pool[i+1:]...)
+			} else {
+				i++
+			}
+		}
+		return taken
+	}
+
+	// groups lists how many cards of each significant rank make up the
+	// category; the remaining ranks of the score are kickers.
+	var groups []int
+	switch hand.Category {
+	case StraightFlush, Straight:
+		high := s.rank(0)
+		for i := 0; i < BestHandSize; i++ {
+			// The Ace of a wheel is its lowest card.
+			hand.Cards = append(hand.Cards, take((high-i+numRanks)%numRanks, 1)...)
+		}
+		return hand, nil
+	case FourOfAKind:
+		groups = []int{4}
+	case FullHouse:
+		groups = []int{3, 2}
+	case Flush:
+		groups = []int{1, 1, 1, 1, 1}
+	case ThreeOfAKind:
+		groups = []int{3}
+	case TwoPair:
+		groups = []int{2, 2}
+	case OnePair:
+		groups = []int{2}
+	case HighCard:
+		groups = []int{1}
+	}
+	for i, n := range groups {
+		hand.Cards = append(hand.Cards, take(s.rank(i), n)...)
+	}
+	for i := len(groups); len(hand.Cards) < BestHandSize; i++ {
+		kicker := take(s.rank(i), 1)
+		hand.Cards = append(hand.Cards, kicker...)
+		hand.Kickers = append(hand.Kickers, kicker...)
+	}
+	return hand, nil
+}
+
+// checkCard checks that c can be part of a poker hand and is not in seen,
+// then adds it to seen.
+func checkCard(c deck.Card, seen map[deck.Card]bool) error {
+	if _, ok := rankIndex[c.Value]; !ok || c.Suit > deck.Hearts {
+		return ErrUnsupportedCard{Card: c}
+	}
+	if seen[c] {
+		return ErrDuplicateCard{Card: c}
+	}
+	seen[c] = true
+	return nil
+}
+
+// flushCards returns the cards of the suit that has five or more of them.
+func flushCards(cards deck.Deck) deck.Deck {
+	counts := make(map[deck.CardSuit]int)
+	for _, c := range cards {
+		counts[c.Suit]++
+	}
+	var flush deck.Deck
+	for _, c := range cards {
+		if counts[c.Suit] >= BestHandSize {
+			flush = append(flush, c)
+		}
+	}
+	return flush
+}
@@ -0,0 +1,185 @@
+package poker
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/AbhilashJN/cards/deck"
+	"github.com/google/go-cmp/cmp"
+)
+
+func codes(d deck.Deck) []string {
+	result := []string{}
+	for _, c := range d {
+		result = append(result, c.Code())
+	}
+	return result
+}
+
+func cardsOf(t *testing.T, cardCodes ...string) deck.Deck {
+	cards, _, err := deck.DrawSpecificCards(mustNewDeck(t), cardCodes)
+	if err != nil {
+		t.Fatalf("Failed to build hand %v: %v", cardCodes, err)
+	}
+	return cards
+}
+
+func mustNewDeck(t *testing.T) deck.Deck {
+	d, err := deck.New(&deck.NewDeckOpts{})
+	if err != nil {
+		t.Fatalf("Failed to create deck: %v", err)
+	}
+	return d
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name             string
+		input            []string
+		expectedCategory Category
+		expectedCards    []string
+		expectedKickers  []string
+	}{
+		{name: "royal flush", input: []string{"AS", "KS", "QS", "JS", "0S", "2H", "3D"}, expectedCategory: StraightFlush, expectedCards: []string{"AS", "KS", "QS", "JS", "0S"}, expectedKickers: []string{}},
+		{name: "steel wheel", input: []string{"3C", "AC", "2C", "5C", "4C", "KC"}, expectedCategory: StraightFlush, expectedCards: []string{"5C", "4C", "3C", "2C", "AC"}, expectedKickers: []string{}},
+		{name: "four of a kind", input: []string{"9S", "9H", "9D", "9C", "KS", "KH", "2D"}, expectedCategory: FourOfAKind, expectedCards: []string{"9S", "9H", "9D", "9C", "KS"}, expectedKickers: []string{"KS"}},
+		{name: "full house from two trips", input: []string{"7S", "7H", "7D", "QS", "QH", "QD", "2C"}, expectedCategory: FullHouse, expectedCards: []string{"QS", "QH", "QD", "7S", "7H"}, expectedKickers: []string{}},
+		{name: "flush beats straight", input: []string{"2H", "7H", "9H", "JH", "KH", "0S", "8D"}, expectedCategory: Flush, expectedCards: []string{"KH", "JH", "9H", "7H", "2H"}, expectedKickers: []string{}},
+		{name: "straight", input: []string{"6S", "7H", "8D", "9C", "0S", "0H", "2C"}, expectedCategory: Straight, expectedCards: []string{"0S", "9C", "8D", "7H", "6S"}, expectedKickers: []string{}},
+		{name: "wheel", input: []string{"AS", "2H", "3D", "4C", "5S"}, expectedCategory: Straight, expectedCards: []string{"5S", "4C", "3D", "2H", "AS"}, expectedKickers: []string{}},
+		{name: "three of a kind", input: []string{"4S", "4H", "4D", "AC", "JS", "9H", "2C"}, expectedCategory: ThreeOfAKind, expectedCards: []string{"4S", "4H", "4D", "AC", "JS"}, expectedKickers: []string{"AC", "JS"}},
+		{name: "two pair of three", input: []string{"4S", "4H", "8D", "8C", "JS", "JH", "2C"}, expectedCategory: TwoPair, expectedCards: []string{"JS", "JH", "8D", "8C", "4S"}, expectedKickers: []string{"4S"}},
+		{name: "one pair", input: []string{"AS", "AH", "3D", "8C", "JS", "9H", "2C"}, expectedCategory: OnePair, expectedCards: []string{"AS", "AH", "JS", "9H", "8C"}, expectedKickers: []string{"JS", "9H", "8C"}},
+		{name: "high card", input: []string{"AS", "KH", "3D", "8C", "JS"}, expectedCategory: HighCard, expectedCards: []string{"AS", "KH", "JS", "8C", "3D"}, expectedKickers: []string{"KH", "JS", "8C", "3D"}},
+	}
+	for _, test := range tests {
+		hand, err := Evaluate(cardsOf(t, test.input...))
+		if err != nil {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, nil, err)
+		}
+		if hand.Category != test.expectedCategory || hand.Score.Category() != test.expectedCategory {
+			t.Errorf("Failed for %s case: expected category to be %v, got %v", test.name, test.expectedCategory, hand.Category)
+		}
+		if cards := codes(hand.Cards); !cmp.Equal(cards, test.expectedCards) {
+			t.Errorf("Failed for %s case: expected cards to be %v, got %v", test.name, test.expectedCards, cards)
+		}
+		if kickers := codes(hand.Kickers); !cmp.Equal(kickers, test.expectedKickers) {
+			t.Errorf("Failed for %s case: expected kickers to be %v, got %v", test.name, test.expectedKickers, kickers)
+		}
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       deck.Deck
+		expectedErr error
+	}{
+		{name: "too few cards", input: cardsOf(t, "AS", "KS", "QS", "JS"), expectedErr: ErrHandSize{Size: 4}},
+		{name: "too many cards", input: cardsOf(t, "AS", "KS", "QS", "JS", "0S", "9S", "8S", "7S"), expectedErr: ErrHandSize{Size: 8}},
+		{name: "duplicate card", input: append(cardsOf(t, "AS", "KS", "QS", "JS"), deck.Card{Value: deck.Ace, Suit: deck.Spades}), expectedErr: ErrDuplicateCard{Card: deck.Card{Value: deck.Ace, Suit: deck.Spades}}},
+		{name: "joker", input: append(cardsOf(t, "AS", "KS", "QS", "JS"), deck.RedJoker), expectedErr: ErrUnsupportedCard{Card: deck.RedJoker}},
+		{name: "tarot card", input: append(cardsOf(t, "AS", "KS", "QS", "JS"), deck.Card{Value: deck.Knight, Suit: deck.Spades}), expectedErr: ErrUnsupportedCard{Card: deck.Card{Value: deck.Knight, Suit: deck.Spades}}},
+	}
+	for _, test := range tests {
+		if _, err := Evaluate(test.input); err != test.expectedErr {
+			t.Errorf("Failed for %s case: expected error to be %v, got %v", test.name, test.expectedErr, err)
+		}
+	}
+}
+
+// TestScoreCardsAllFiveCardHands checks the number of hands of every category
+// and the number of distinct hand values against the well known totals.
+func TestScoreCardsAllFiveCardHands(t *testing.T) {
+	full := mustNewDeck(t)
+	expectedCounts := map[Category]int{
+		StraightFlush: 40, FourOfAKind: 624, FullHouse: 3744, Flush: 5108, Straight: 10200,
+		ThreeOfAKind: 54912, TwoPair: 123552, OnePair: 1098240, HighCard: 1302540,
+	}
+	counts := make(map[Category]int)
+	distinct := make(map[Score]bool)
+	hand := make(deck.Deck, 5)
+	for a := 0; a < 52; a++ {
+		for b := a + 1; b < 52; b++ {
+			for c := b + 1; c < 52; c++ {
+				for d := c + 1; d < 52; d++ {
+					for e := d + 1; e < 52; e++ {
+						hand[0], hand[1], hand[2], hand[3], hand[4] = full[a], full[b], full[c], full[d], full[e]
+						s := ScoreCards(hand)
+						counts[s.Category()]++
+						distinct[s] = true
+					}
+				}
+			}
+		}
+	}
+	if !cmp.Equal(counts, expectedCounts) {
+		t.Errorf("Failed for all five card hands: expected category counts %v, got %v", expectedCounts, counts)
+	}
+	if len(distinct) != 7462 {
+		t.Errorf("Failed for all five card hands: expected %d distinct scores, got %d", 7462, len(distinct))
+	}
+}
+
+// TestScoreCardsBestOfSeven checks that seven card hands score as their best
+// five card combination.
+func TestScoreCardsBestOfSeven(t *testing.T) {
+	full := mustNewDeck(t)
+	rng := rand.New(rand.NewSource(7))
+	for i := 0; i < 2000; i++ {
+		rng.Shuffle(len(full), func(i, j int) { full[i], full[j] = full[j], full[i] })
+		seven := full[:7]
+		best := Score(0)
+		five := make(deck.Deck, 0, 5)
+		for skip1 := 0; skip1 < 7; skip1++ {
+			for skip2 := skip1 + 1; skip2 < 7; skip2++ {
+				five = five[:0]
+				for k, c := range seven {
+					if k != skip1 && k != skip2 {
+						five = append(five, c)
+					}
+				}
+				if s := ScoreCards(five); s > best {
+					best = s
+				}
+			}
+		}
+		if s := ScoreCards(seven); s != best {
+			t.Fatalf("Failed for %v: expected score %d, got %d", codes(seven), best, s)
+		}
+		hand, _ := Evaluate(seven)
+		if s := ScoreCards(hand.Cards); s != best {
+			t.Fatalf("Failed for %v: expected the best five cards %v to score %d, got %d", codes(seven), codes(hand.Cards), best, s)
+		}
+	}
+}
+
+func TestScoreOrdering(t *testing.T) {
+	tests := []struct {
+		name          string
+		better, worse []string
+	}{
+		{name: "kicker", better: []string{"AS", "AH", "KD", "7C", "2S"}, worse: []string{"AD", "AC", "QD", "7H", "2H"}},
+		{name: "second pair", better: []string{"9S", "9H", "5D", "5C", "2S"}, worse: []string{"9D", "9C", "4D", "4C", "AS"}},
+		{name: "wheel is lowest straight", better: []string{"2S", "3H", "4D", "5C", "6S"}, worse: []string{"AS", "2H", "3D", "4C", "5S"}},
+		{name: "ace high beats king high", better: []string{"AS", "2H", "3D", "4C", "7S"}, worse: []string{"KS", "QH", "JD", "9C", "8S"}},
+	}
+	for _, test := range tests {
+		better, worse := ScoreCards(cardsOf(t, test.better...)), ScoreCards(cardsOf(t, test.worse...))
+		if better <= worse {
+			t.Errorf("Failed for %s case: expected %v to beat %v, got scores %d and %d", test.name, test.better, test.worse, better, worse)
+		}
+	}
+	split := ScoreCards(cardsOf(t, "AS", "KS", "QH", "JD", "9C"))
+	if other := ScoreCards(cardsOf(t, "AH", "KH", "QD", "JC", "9S")); other != split {
+		t.Errorf("Failed for split case: expected equal scores, got %d and %d", split, other)
+	}
+}
+
+func BenchmarkScoreCards(b *testing.B) {
+	d, _ := deck.New(&deck.NewDeckOpts{})
+	seven := d[10:17]
+	for i := 0; i < b.N; i++ {
+		ScoreCards(seven)
+	}
+}
@@ -5,13 +5,16 @@ import (
 	"log"
 	"net/http"
 
+	"github.com/AbhilashJN/cards/database"
 	"github.com/julienschmidt/httprouter"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type server struct {
-	router   *httprouter.Router
-	dbClient *mongo.Database
+	router *httprouter.Router
+	decks  database.DeckCRUDer
+	// adminToken is the bearer token that privileged requests must carry.
+	// If it is empty no request is privileged.
+	adminToken string
 }
 
 func crashHandler(w http.ResponseWriter, r *http.Request, err interface{}) {
@@ -33,7 +36,20 @@ func (s *server) initRouter() {
 	s.router.PanicHandler = crashHandler
 	s.router.GET("/status", Status)
 	s.router.POST("/deck", s.handleCreateDeck)
+	s.router.GET("/deck", s.handleListDecks)
 	s.router.GET("/deck/:uuid", s.handleGetDeck)
 	s.router.PATCH("/deck/:uuid", s.handleDrawCards)
+	s.router.DELETE("/deck/:uuid", s.handleDeleteDeck)
+	s.router.POST("/deck/:uuid/pile/:name", s.handleAddToPile)
+	s.router.GET("/deck/:uuid/pile/:name", s.handleGetPile)
+	s.router.PATCH("/deck/:uuid/pile/:name", s.handleDrawFromPile)
+	s.router.POST("/deck/:uuid/return", s.handleReturnCards)
+	s.router.POST("/deck/:uuid/shuffle", s.handleShuffleDeck)
+	s.router.POST("/deck/:uuid/seed", s.handleSeedDeck)
+	s.router.GET("/deck/:uuid/reveal", s.handleRevealDeck)
+	s.router.GET("/deck/:uuid/peek", s.handlePeekCards)
+	s.router.POST("/deck/:uuid/close", s.handleCloseDeck)
+	s.router.POST("/tools/evaluate", s.handleEvaluateHand)
+	s.router.POST("/tools/equity", s.handleEquity)
 
 }